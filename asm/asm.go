@@ -0,0 +1,438 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package asm assembles programs written in the mnemonics of
+// chip8.Opcode.String into ROM images.
+//
+// Each line holds an optional label, an instruction or directive, and an
+// optional comment:
+//
+//	loop:   LD V0, K        ; wait for a key
+//	        SE V0, 0F
+//	        JP loop
+//	sprite: DB %11110000, $90, #90, 0x90, F0
+//	speed = 2A
+//
+// Numbers are hexadecimal, with or without a 0x, # or $ prefix, or binary
+// with a % prefix. Wherever a number is expected, a label or constant may
+// be used instead, and terms may be added or subtracted. Constants are
+// defined with "name = value" or "name EQU value". DB emits bytes and DW
+// emits big-endian words. Mnemonics, registers and names are not case
+// sensitive.
+package asm
+
+import (
+	"cmp"
+	"emul8/chip8"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Error describes a problem at a line and column of the source, both
+// counted from one.
+type Error struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Col) + ": " + e.Msg
+}
+
+// ErrorList holds every problem found in a source, in order.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	var b strings.Builder
+	for i, e := range l {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+// statement is a line of source after its label and comment are removed.
+type statement struct {
+	line     int
+	col      int // Column of the mnemonic.
+	mnemonic string
+	operands []operand
+	bad      bool // An operand could not be read, and has been reported.
+}
+
+type assembler struct {
+	statements []statement
+	labels     map[string]uint16
+	constants  map[string]operand
+	resolving  map[string]bool
+	errs       ErrorList
+}
+
+func (a *assembler) errorf(line, col int, msg string) {
+	a.errs = append(a.errs, &Error{Line: line, Col: col, Msg: msg})
+}
+
+// Assemble returns the ROM image described by src, which is loaded at
+// chip8.ProgramStartAddress.
+func Assemble(src []byte) ([]byte, error) {
+	a := &assembler{
+		labels:    make(map[string]uint16),
+		constants: make(map[string]operand),
+		resolving: make(map[string]bool),
+	}
+
+	a.parse(string(src))
+
+	var rom []byte
+	for _, s := range a.statements {
+		rom = a.encode(rom, s)
+	}
+
+	if len(a.errs) > 0 {
+		slices.SortStableFunc(a.errs, func(x, y *Error) int {
+			return cmp.Or(x.Line-y.Line, x.Col-y.Col)
+		})
+		return nil, a.errs
+	}
+	return rom, nil
+}
+
+// parse splits the source into statements and records where each label
+// falls. The size of every statement is known without evaluating its
+// operands, so one pass is enough to place every label.
+func (a *assembler) parse(src string) {
+	addr := int(chip8.ProgramStartAddress)
+
+	for i, text := range strings.Split(src, "\n") {
+		line := i + 1
+
+		if semi := strings.IndexByte(text, ';'); semi >= 0 {
+			text = text[:semi]
+		}
+		text = strings.TrimRight(text, " \t\r")
+
+		col := 1
+		skip := func(n int) {
+			col += n
+			text = text[n:]
+		}
+		skip(len(text) - len(strings.TrimLeft(text, " \t")))
+
+		// Labels end with a colon, and may share a line with a statement.
+		for {
+			name, rest, ok := strings.Cut(text, ":")
+			if !ok || !isName(name) {
+				break
+			}
+			a.define(line, col, name)
+			a.labels[strings.ToUpper(name)] = uint16(addr)
+
+			skip(len(text) - len(rest))
+			skip(len(text) - len(strings.TrimLeft(text, " \t")))
+		}
+
+		if text == "" {
+			continue
+		}
+
+		mnemonic, rest, _ := strings.Cut(text, " ")
+		if m, r, ok := strings.Cut(mnemonic, "\t"); ok {
+			mnemonic, rest = m, r+" "+rest
+		}
+
+		// Constants: "name = value" or "name EQU value".
+		if op, value, ok := cutConstant(rest); ok && isName(mnemonic) {
+			valueCol := col + len(text) - len(value)
+			operands, ok := a.operands(line, valueCol, value)
+			if !ok {
+				continue
+			}
+			if len(operands) != 1 || operands[0].terms == nil || operands[0].kind == kindLong {
+				a.errorf(line, valueCol, op+" needs a single value")
+				continue
+			}
+			a.define(line, col, mnemonic)
+			a.constants[strings.ToUpper(mnemonic)] = operands[0]
+			continue
+		}
+
+		operands, ok := a.operands(line, col+len(mnemonic), text[len(mnemonic):])
+		s := statement{
+			line:     line,
+			col:      col,
+			mnemonic: strings.ToUpper(mnemonic),
+			operands: operands,
+			bad:      !ok,
+		}
+		a.statements = append(a.statements, s)
+
+		switch s.mnemonic {
+		case "DB":
+			addr += len(s.operands)
+		case "DW":
+			addr += 2 * len(s.operands)
+		default:
+			addr += 2
+			if s.mnemonic == "LD" && len(s.operands) == 2 && s.operands[1].kind == kindLong {
+				addr += 2
+			}
+		}
+
+		if addr > chip8.ExtendedMemorySize {
+			a.errorf(line, col, "program does not fit in memory")
+			return
+		}
+	}
+}
+
+func cutConstant(rest string) (op, value string, ok bool) {
+	rest = strings.TrimSpace(rest)
+	if value, ok := strings.CutPrefix(rest, "="); ok {
+		return "=", value, true
+	}
+
+	if len(rest) > 3 && strings.EqualFold(rest[:3], "EQU") && (rest[3] == ' ' || rest[3] == '\t') {
+		return "EQU", rest[4:], true
+	}
+	return "", "", false
+}
+
+func (a *assembler) define(line, col int, name string) {
+	key := strings.ToUpper(name)
+
+	_, isLabel := a.labels[key]
+	_, isConstant := a.constants[key]
+	switch {
+	case isLabel || isConstant:
+		a.errorf(line, col, name+" is already defined")
+	case reserved[key] || isRegister(key):
+		a.errorf(line, col, name+" is a reserved word")
+	case isNumber(key):
+		a.errorf(line, col, name+" would be read as a number")
+	}
+}
+
+// reserved are the operands that cannot be used as names.
+var reserved = map[string]bool{
+	"I": true, "DT": true, "ST": true, "K": true, "F": true, "HF": true,
+	"B": true, "R": true, "LONG": true, "EQU": true,
+}
+
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isRegister(s string) bool {
+	_, ok := register(s)
+	return ok
+}
+
+func register(s string) (uint8, bool) {
+	if len(s) != 2 || (s[0] != 'V' && s[0] != 'v') {
+		return 0, false
+	}
+
+	x, err := strconv.ParseUint(s[1:], 16, 4)
+	return uint8(x), err == nil
+}
+
+func isNumber(s string) bool {
+	_, ok := parseNumber(s)
+	return ok
+}
+
+// parseNumber reads a hexadecimal or binary number.
+func parseNumber(s string) (int, bool) {
+	base := 16
+	switch {
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0X"):
+		s = s[2:]
+	case strings.HasPrefix(s, "#"), strings.HasPrefix(s, "$"):
+		s = s[1:]
+	case strings.HasPrefix(s, "%"):
+		s, base = s[1:], 2
+	}
+
+	n, err := strconv.ParseUint(s, base, 32)
+	return int(n), err == nil && s != ""
+}
+
+func (a *assembler) encode(rom []byte, s statement) []byte {
+	if s.bad {
+		return rom
+	}
+
+	switch s.mnemonic {
+	case "DB", "DW":
+		if len(s.operands) == 0 {
+			a.errorf(s.line, s.col, s.mnemonic+" needs at least one value")
+			return rom
+		}
+
+		bits := 8
+		if s.mnemonic == "DW" {
+			bits = 16
+		}
+
+		for _, op := range s.operands {
+			if op.terms == nil || op.kind == kindLong {
+				a.errorf(s.line, op.col, s.mnemonic+" takes only values")
+				continue
+			}
+
+			v := a.value(op, bits)
+			if bits == 16 {
+				rom = append(rom, byte(v>>8))
+			}
+			rom = append(rom, byte(v))
+		}
+		return rom
+	}
+
+	f, ok := a.match(s)
+	if !ok {
+		return rom
+	}
+
+	word := uint16(f.instruction.Pattern)
+	var long uint16
+
+	for i, k := range f.operands {
+		op := s.operands[i]
+		shift, mask := k.Field()
+
+		switch k {
+		case chip8.OperandVX, chip8.OperandVY, chip8.OperandOptionalVY:
+			word |= uint16(op.x) << shift
+		case chip8.OperandRange:
+			word |= (uint16(op.x)<<4 | uint16(op.y)) << shift
+		case chip8.OperandLong:
+			long = uint16(a.value(op, 16))
+		default:
+			if mask != 0 {
+				word |= uint16(a.value(op, bits.OnesCount16(uint16(mask)))) << shift
+			}
+		}
+	}
+
+	rom = append(rom, byte(word>>8), byte(word))
+	if f.instruction.Size == 4 {
+		rom = append(rom, byte(long>>8), byte(long))
+	}
+	return rom
+}
+
+// match finds the form of an instruction that takes the statement's
+// operands.
+func (a *assembler) match(s statement) (form, bool) {
+	candidates, ok := forms[s.mnemonic]
+	if !ok {
+		a.errorf(s.line, s.col, "unknown instruction "+s.mnemonic)
+		return form{}, false
+	}
+
+	for _, f := range candidates {
+		if len(f.operands) != len(s.operands) {
+			continue
+		}
+
+		fits := true
+		for i, k := range f.operands {
+			fits = fits && accepts(k, s.operands[i])
+		}
+		if fits {
+			return f, true
+		}
+	}
+
+	a.errorf(s.line, s.col, s.mnemonic+" does not take these operands")
+	return form{}, false
+}
+
+// value evaluates a number operand, which must fit in the given number of
+// bits.
+func (a *assembler) value(op operand, bits int) int {
+	v, ok := a.eval(op)
+	if !ok {
+		return 0
+	}
+
+	if v < 0 || v >= 1<<bits {
+		a.errorf(op.line, op.col, "value "+strings.ToUpper(strconv.FormatInt(int64(v), 16))+" does not fit in "+strconv.Itoa(bits)+" bits")
+		return 0
+	}
+	return v
+}
+
+func (a *assembler) eval(op operand) (int, bool) {
+	var sum int
+
+	for _, t := range op.terms {
+		v, ok := parseNumber(t.text)
+		if !ok {
+			v, ok = a.symbol(op, t)
+			if !ok {
+				return 0, false
+			}
+		}
+
+		if t.negative {
+			v = -v
+		}
+		sum += v
+	}
+	return sum, true
+}
+
+func (a *assembler) symbol(op operand, t term) (int, bool) {
+	key := strings.ToUpper(t.text)
+
+	if addr, ok := a.labels[key]; ok {
+		return int(addr), true
+	}
+
+	c, ok := a.constants[key]
+	if !ok {
+		a.errorf(op.line, t.col, "undefined name "+t.text)
+		return 0, false
+	}
+
+	if a.resolving[key] {
+		a.errorf(op.line, t.col, "constant "+t.text+" is defined in terms of itself")
+		return 0, false
+	}
+
+	a.resolving[key] = true
+	defer delete(a.resolving, key)
+	return a.eval(c)
+}
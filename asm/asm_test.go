@@ -0,0 +1,222 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package asm
+
+import (
+	"emul8/chip8"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// instructions gives a way of writing every form, as chip8.Opcode.String
+// writes it where it can.
+var instructions = []struct {
+	src  string
+	want []byte
+}{
+	{"CLS", []byte{0x00, 0xE0}},
+	{"RET", []byte{0x00, 0xEE}},
+	{"SCD 4", []byte{0x00, 0xC4}},
+	{"SCU 4", []byte{0x00, 0xD4}},
+	{"SCR", []byte{0x00, 0xFB}},
+	{"SCL", []byte{0x00, 0xFC}},
+	{"EXIT", []byte{0x00, 0xFD}},
+	{"LOW", []byte{0x00, 0xFE}},
+	{"HIGH", []byte{0x00, 0xFF}},
+	{"JP 234", []byte{0x12, 0x34}},
+	{"CALL 234", []byte{0x22, 0x34}},
+	{"SE V2, 34", []byte{0x32, 0x34}},
+	{"SNE V2, 34", []byte{0x42, 0x34}},
+	{"SE V2, V3", []byte{0x52, 0x30}},
+	{"LD [I], V2-V3", []byte{0x52, 0x32}},
+	{"LD V2-V3, [I]", []byte{0x52, 0x33}},
+	{"LD V2, 34", []byte{0x62, 0x34}},
+	{"ADD V2, 34", []byte{0x72, 0x34}},
+	{"LD V2, V3", []byte{0x82, 0x30}},
+	{"OR V2, V3", []byte{0x82, 0x31}},
+	{"AND V2, V3", []byte{0x82, 0x32}},
+	{"XOR V2, V3", []byte{0x82, 0x33}},
+	{"ADD V2, V3", []byte{0x82, 0x34}},
+	{"SUB V2, V3", []byte{0x82, 0x35}},
+	{"SHR V2, V3", []byte{0x82, 0x36}},
+	{"SHR V2", []byte{0x82, 0x06}},
+	{"SUBN V2, V3", []byte{0x82, 0x37}},
+	{"SHL V2, V3", []byte{0x82, 0x3E}},
+	{"SHL V2", []byte{0x82, 0x0E}},
+	{"SNE V2, V3", []byte{0x92, 0x30}},
+	{"LD I, 234", []byte{0xA2, 0x34}},
+	{"JP V0, 234", []byte{0xB2, 0x34}},
+	{"RND V2, 34", []byte{0xC2, 0x34}},
+	{"DRW V2, V3, 4", []byte{0xD2, 0x34}},
+	{"SKP V2", []byte{0xE2, 0x9E}},
+	{"SKNP V2", []byte{0xE2, 0xA1}},
+	{"LD I, LONG 1234", []byte{0xF0, 0x00, 0x12, 0x34}},
+	{"PLANE 2", []byte{0xF2, 0x01}},
+	{"AUDIO", []byte{0xF0, 0x02}},
+	{"LD V2, DT", []byte{0xF2, 0x07}},
+	{"LD V2, K", []byte{0xF2, 0x0A}},
+	{"LD DT, V2", []byte{0xF2, 0x15}},
+	{"LD ST, V2", []byte{0xF2, 0x18}},
+	{"ADD I, V2", []byte{0xF2, 0x1E}},
+	{"LD F, V2", []byte{0xF2, 0x29}},
+	{"LD HF, V2", []byte{0xF2, 0x30}},
+	{"LD B, V2", []byte{0xF2, 0x33}},
+	{"PITCH V2", []byte{0xF2, 0x3A}},
+	{"LD [I], V2", []byte{0xF2, 0x55}},
+	{"LD V2, [I]", []byte{0xF2, 0x65}},
+	{"LD R, V2", []byte{0xF2, 0x75}},
+	{"LD V2, R", []byte{0xF2, 0x85}},
+}
+
+func TestInstructions(t *testing.T) {
+	type key struct {
+		in       *chip8.Instruction
+		operands int
+	}
+	covered := make(map[key]bool)
+
+	for _, tt := range instructions {
+		rom, err := Assemble([]byte(tt.src))
+		if err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+			continue
+		}
+		if !slices.Equal(rom, tt.want) {
+			t.Errorf("%s = % X, want % X", tt.src, rom, tt.want)
+			continue
+		}
+
+		in, _ := chip8.Decode(chip8.Opcode(uint16(rom[0])<<8 | uint16(rom[1])))
+		operands := 0
+		if _, rest, ok := strings.Cut(tt.src, " "); ok {
+			operands = strings.Count(rest, ",") + 1
+		}
+		covered[key{in, operands}] = true
+	}
+
+	for mnemonic, fs := range forms {
+		for _, f := range fs {
+			if !covered[key{f.instruction, len(f.operands)}] {
+				t.Errorf("no test of %s with operands %v", mnemonic, f.operands)
+			}
+		}
+	}
+}
+
+// TestOpcodeStrings assembles what chip8.Opcode.String writes for every
+// instruction, which disasm relies on.
+func TestOpcodeStrings(t *testing.T) {
+	for _, in := range chip8.Instructions {
+		if in.Size != 2 {
+			continue
+		}
+
+		var fields chip8.Opcode
+		for _, k := range in.Operands {
+			_, mask := k.Field()
+			fields |= mask
+		}
+		op := in.Pattern | 0x5A7C&fields
+
+		rom, err := Assemble([]byte(op.String()))
+		if err != nil {
+			t.Errorf("%s: %v", op, err)
+			continue
+		}
+		if got := chip8.Opcode(uint16(rom[0])<<8 | uint16(rom[1])); got != op {
+			t.Errorf("%s = %04X, want %04X", op, uint16(got), uint16(op))
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	src := `
+; Draws a sprite at (x, y) until a key is pressed.
+x = 10
+y EQU x+4
+start:	LD V0, x
+	LD V1, y        ; comment
+loop:	LD I, sprite
+	DRW V0, V1, 3
+	sknp v0
+	jp loop
+	JP start
+sprite: DB %11110000, $90, #90, 0x90
+words:	DW sprite-start, 1
+	LD I, LONG words+2
+`
+	want := []byte{
+		0x60, 0x10, // LD V0, x
+		0x61, 0x14, // LD V1, y
+		0xA2, 0x0E, // loop: LD I, sprite
+		0xD0, 0x13,
+		0xE0, 0xA1,
+		0x12, 0x04, // JP loop
+		0x12, 0x00, // JP start
+		0xF0, 0x90, 0x90, 0x90, // sprite
+		0x00, 0x0E, 0x00, 0x01, // words
+		0xF0, 0x00, 0x02, 0x14, // LD I, LONG words+2
+	}
+
+	rom, err := Assemble([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(rom, want) {
+		t.Errorf("Assemble() =\n% X\nwant\n% X", rom, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"FOO V1", "1:1: unknown instruction FOO"},
+		{"  LD V1, I", "1:3: LD does not take these operands"},
+		{"LD V1, VG", "1:8: undefined name VG"},
+		{"JP nowhere", "1:4: undefined name nowhere"},
+		{"LD V1, 100", "1:8: value 100 does not fit in 8 bits"},
+		{"DB 1,,2", "1:6: missing value"},
+		{"DB 1, ?", "1:7: bad value ?"},
+		{"loop:\nloop: CLS", "2:1: loop is already defined"},
+		{"I: CLS", "1:1: I is a reserved word"},
+		{"p = q+1\nq = p\nJP p", "2:5: constant p is defined in terms of itself"},
+		{"DW", "1:1: DW needs at least one value"},
+		{"DB V1", "1:4: DB takes only values"},
+		{"x = LONG 2", "1:4: = needs a single value"},
+		{"CLS\nJP x\nLD V1, 1FF", "2:4: undefined name x\n3:8: value 1FF does not fit in 8 bits"},
+	}
+
+	for _, tt := range tests {
+		_, err := Assemble([]byte(tt.src))
+		if err == nil {
+			t.Errorf("%q assembled", tt.src)
+			continue
+		}
+
+		var list ErrorList
+		if !errors.As(err, &list) {
+			t.Errorf("%q: error %T is not an ErrorList", tt.src, err)
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%q: error %q, want %q", tt.src, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package asm
+
+import (
+	"emul8/chip8"
+	"strings"
+)
+
+type kind uint8
+
+const (
+	kindNumber   kind = iota // A value, such as 2A or loop+2.
+	kindRegister             // V0 to VF.
+	kindRange                // Vx-Vy.
+	kindLong                 // LONG followed by a value.
+	kindI
+	kindIndirect // [I]
+	kindDT
+	kindST
+	kindK
+	kindF
+	kindHF
+	kindB
+	kindR
+)
+
+var keywords = map[string]kind{
+	"I":   kindI,
+	"[I]": kindIndirect,
+	"DT":  kindDT,
+	"ST":  kindST,
+	"K":   kindK,
+	"F":   kindF,
+	"HF":  kindHF,
+	"B":   kindB,
+	"R":   kindR,
+}
+
+// term is a number or name in a value, added or subtracted.
+type term struct {
+	text     string
+	col      int
+	negative bool
+}
+
+type operand struct {
+	kind  kind
+	line  int
+	col   int
+	x, y  uint8  // The registers of a register or range.
+	terms []term // The value of a number, or of LONG.
+}
+
+// operands splits the text after a mnemonic at its commas. The column is
+// that of the first character of text. It reports false when an operand
+// could not be read.
+func (a *assembler) operands(line, col int, text string) ([]operand, bool) {
+	if strings.TrimSpace(text) == "" {
+		return nil, true
+	}
+
+	var ops []operand
+	good := true
+	for _, field := range strings.Split(text, ",") {
+		lead := len(field) - len(strings.TrimLeft(field, " \t"))
+		op, ok := a.operand(line, col+lead, strings.TrimSpace(field))
+		ops = append(ops, op)
+		good = good && ok
+		col += len(field) + 1
+	}
+	return ops, good
+}
+
+func (a *assembler) operand(line, col int, text string) (operand, bool) {
+	op := operand{line: line, col: col}
+	upper := strings.ToUpper(text)
+
+	if k, ok := keywords[upper]; ok {
+		op.kind = k
+		// F and B are also numbers.
+		if isNumber(upper) {
+			op.terms = []term{{text: text, col: col}}
+		}
+		return op, true
+	}
+
+	if x, ok := register(upper); ok {
+		op.kind, op.x = kindRegister, x
+		return op, true
+	}
+
+	if first, last, ok := strings.Cut(upper, "-"); ok {
+		x, okX := register(strings.TrimSpace(first))
+		y, okY := register(strings.TrimSpace(last))
+		if okX && okY {
+			op.kind, op.x, op.y = kindRange, x, y
+			return op, true
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(upper, "LONG"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+		op.kind = kindLong
+		skip := len(text) - len(strings.TrimLeft(text[4:], " \t"))
+		text, col = text[skip:], col+skip
+	}
+
+	terms, ok := a.terms(line, col, text)
+	op.terms = terms
+	return op, ok
+}
+
+// terms splits a value into numbers and names joined by + and -.
+func (a *assembler) terms(line, col int, text string) ([]term, bool) {
+	var terms []term
+	negative := false
+
+	for {
+		lead := len(text) - len(strings.TrimLeft(text, " \t"))
+		text, col = text[lead:], col+lead
+
+		// A leading sign applies to the first term.
+		if len(terms) == 0 && (strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+")) {
+			negative = text[0] == '-'
+			text, col = text[1:], col+1
+			continue
+		}
+
+		end := strings.IndexAny(text, "+-")
+		word := text
+		if end >= 0 {
+			word = text[:end]
+		}
+		word = strings.TrimRight(word, " \t")
+
+		if !isNumber(word) && !isName(word) {
+			if word == "" {
+				a.errorf(line, col, "missing value")
+			} else {
+				a.errorf(line, col, "bad value "+word)
+			}
+			return nil, false
+		}
+		terms = append(terms, term{text: word, col: col, negative: negative})
+
+		if end < 0 {
+			return terms, true
+		}
+		negative = text[end] == '-'
+		text, col = text[end+1:], col+end+1
+	}
+}
+
+// accepts reports whether an operand can be written where an instruction
+// takes an operand of kind k.
+func accepts(k chip8.OperandKind, op operand) bool {
+	switch k {
+	case chip8.OperandVX, chip8.OperandVY, chip8.OperandOptionalVY:
+		return op.kind == kindRegister
+	case chip8.OperandV0:
+		return op.kind == kindRegister && op.x == 0
+	case chip8.OperandRange:
+		return op.kind == kindRange
+	case chip8.OperandNibble, chip8.OperandHeight, chip8.OperandPlanes, chip8.OperandByte, chip8.OperandAddress:
+		return op.kind != kindLong && op.terms != nil
+	case chip8.OperandLong:
+		return op.kind == kindLong
+	}
+	fixed, ok := keywords[k.Keyword()]
+	return ok && op.kind == fixed
+}
+
+// form is one way of writing an instruction: its operands and the
+// instruction they are encoded into.
+type form struct {
+	operands    []chip8.OperandKind
+	instruction *chip8.Instruction
+}
+
+// forms holds the ways of writing each instruction by mnemonic. An
+// optional operand gives a form without it, which leaves its field zero.
+var forms = func() map[string][]form {
+	forms := make(map[string][]form)
+	for i := range chip8.Instructions {
+		in := &chip8.Instructions[i]
+
+		var short []chip8.OperandKind
+		for _, k := range in.Operands {
+			if k != chip8.OperandOptionalVY {
+				short = append(short, k)
+			}
+		}
+		if len(short) < len(in.Operands) {
+			forms[in.Mnemonic] = append(forms[in.Mnemonic], form{short, in})
+		}
+		forms[in.Mnemonic] = append(forms[in.Mnemonic], form{in.Operands, in})
+	}
+	return forms
+}()
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package asm_test
+
+import (
+	"bytes"
+	"emul8/asm"
+	"emul8/conformance"
+	"emul8/disasm"
+	"io/fs"
+	"path"
+	"testing"
+)
+
+// TestRoundTrip assembles the disassembly of every ROM of the conformance
+// corpus, which must give back the same ROM.
+func TestRoundTrip(t *testing.T) {
+	names, err := fs.Glob(conformance.Corpus, "roms/*.hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatal("no ROMs in the corpus")
+	}
+
+	for _, name := range names {
+		t.Run(path.Base(name), func(t *testing.T) {
+			src, err := fs.ReadFile(conformance.Corpus, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rom, err := conformance.ParseHex(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			source := disasm.Disassemble(rom)
+			again, err := asm.Assemble([]byte(source))
+			if err != nil {
+				t.Fatalf("%v in\n%s", err, source)
+			}
+			if !bytes.Equal(again, rom) {
+				t.Errorf("round trip gave\n% X\nwant\n% X", again, rom)
+			}
+		})
+	}
+}
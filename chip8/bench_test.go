@@ -0,0 +1,93 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+// benchPrograms keep the processor busy, as the loops emul8 bench measures.
+var benchPrograms = []struct {
+	name string
+	rom  []byte
+}{
+	{"arith", []byte{
+		0x60, 0x00, // 200: LD V0, 00
+		0x61, 0x01, // 202: LD V1, 01
+		0x80, 0x14, // 204: ADD V0, V1
+		0x82, 0x00, // 206: LD V2, V0
+		0x82, 0x06, // 208: SHR V2
+		0x83, 0x23, // 20A: XOR V3, V2
+		0x74, 0x03, // 20C: ADD V4, 03
+		0x85, 0x45, // 20E: SUB V5, V4
+		0x86, 0x51, // 210: OR V6, V5
+		0x86, 0x32, // 212: AND V6, V3
+		0x87, 0x60, // 214: LD V7, V6
+		0x87, 0x0E, // 216: SHL V7
+		0x71, 0x01, // 218: ADD V1, 01
+		0x31, 0x00, // 21A: SE V1, 00
+		0x12, 0x04, // 21C: JP 204
+		0x12, 0x00, // 21E: JP 200
+	}},
+	{"draw", []byte{
+		0xA2, 0x0A, // 200: LD I, 20A
+		0xD0, 0x15, // 202: DRW V0, V1, 5
+		0x70, 0x01, // 204: ADD V0, 01
+		0x71, 0x02, // 206: ADD V1, 02
+		0x12, 0x00, // 208: JP 200
+		0xF0, 0x90, 0xF0, 0x90, 0xF0,
+	}},
+}
+
+// benchEngines runs f for each program under each engine, in a stable
+// order.
+func benchEngines(b *testing.B, f func(b *testing.B, p *Processor)) {
+	for _, prog := range benchPrograms {
+		for _, engine := range slices.Sorted(maps.Keys(Engines)) {
+			b.Run(prog.name+"/"+engine, func(b *testing.B) {
+				p := NewProcessor(append([]Option{WithSeed(1)}, Engines[engine]...)...)
+				if err := p.Load(prog.rom); err != nil {
+					b.Fatal(err)
+				}
+				f(b, p)
+				b.ReportMetric(float64(p.Cycles())/b.Elapsed().Seconds(), "instr/s")
+			})
+		}
+	}
+}
+
+func BenchmarkStep(b *testing.B) {
+	benchEngines(b, func(b *testing.B, p *Processor) {
+		for b.Loop() {
+			if _, err := p.Step(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkRunFrame(b *testing.B) {
+	benchEngines(b, func(b *testing.B, p *Processor) {
+		for b.Loop() {
+			if _, err := p.RunFrame(DefaultCyclesPerFrame); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+// decoded is an instruction decoded from memory, ready to execute. An empty
+// entry has not been decoded.
+type decoded struct {
+	op Opcode
+	in *Instruction
+}
+
+// WithDecodeCache keeps the instruction decoded at each address, so that an
+// instruction is decoded once rather than every time it runs. Writes to
+// memory drop the entries they overlap, which keeps self-modifying programs
+// correct. The cache takes memory in proportion to the address space.
+func WithDecodeCache() Option {
+	return func(p *Processor) {
+		p.caching = true
+	}
+}
+
+// fetch returns the opcode at pc and its instruction, which is nil when the
+// opcode is not an instruction of the processor's mode.
+func (p *Processor) fetch(pc uint16) (Opcode, *Instruction, error) {
+	if int(pc) < len(p.cache) {
+		if d := p.cache[pc]; d.in != nil {
+			return d.op, d.in, nil
+		}
+	}
+
+	op, err := p.OpcodeAt(pc)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	in, ok := Decode(op)
+	if !ok || !p.supports(in.Mode) {
+		return op, nil, nil
+	}
+
+	if int(pc) < len(p.cache) {
+		p.cache[pc] = decoded{op: op, in: in}
+	}
+	return op, in, nil
+}
+
+// invalidate drops the cached instructions and compiled blocks that overlap
+// the n bytes of memory starting at addr, including the instruction whose
+// second byte is at addr.
+func (p *Processor) invalidate(addr uint16, n int) {
+	p.dropBlocks(addr, n)
+	if p.cache == nil {
+		return
+	}
+
+	start := max(int(addr)-1, 0)
+	end := min(int(addr)+n, len(p.cache))
+	if start < end {
+		clear(p.cache[start:end])
+	}
+}
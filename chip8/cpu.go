@@ -17,6 +17,7 @@
 package chip8
 
 import (
+	"math/rand/v2"
 	"sync/atomic"
 	"time"
 )
@@ -32,18 +33,63 @@ const (
 	TimerRate time.Duration = time.Second / 60  // 60hz
 	ClockRate time.Duration = time.Second / 700 // 700hz
 
+	// DefaultCyclesPerFrame is the number of instructions run between timer
+	// ticks at ClockRate.
+	DefaultCyclesPerFrame int = int(TimerRate / ClockRate)
+
 	Width  int = 64
 	Height int = 32
 	Area   int = Width * Height
+
+	HiResWidth  int = 128
+	HiResHeight int = 64
+	HiResArea   int = HiResWidth * HiResHeight
+
+	BigFontStartAddress uint16 = FontStartAddress + 16*5
+	FlagCount           int    = 16
+
+	MemorySize         int   = 0x1000
+	ExtendedMemorySize int   = 0x10000
+	PatternSize        int   = 16
+	DefaultPitch       uint8 = 64
 )
 
 const (
 	Delay uint8 = 1 << iota
 	Sound
 	Redraw
+	Halt
+
+	// Stall marks an instruction that waits, for the vertical blank or a
+	// key, by leaving the program counter on itself. A stalled instruction
+	// is replayed rather than executed, so it is not counted as a cycle or
+	// traced.
+	Stall
+)
+
+type Mode uint8
+
+const (
+	ModeCHIP8 Mode = iota
+	ModeSCHIP
+	ModeXOCHIP
 )
 
-var buffer [2]byte
+// Modes maps the names accepted by frontends to the execution modes.
+var Modes = map[string]Mode{
+	"chip8":  ModeCHIP8,
+	"schip":  ModeSCHIP,
+	"xochip": ModeXOCHIP,
+}
+
+func (m Mode) String() string {
+	for name, mode := range Modes {
+		if mode == m {
+			return name
+		}
+	}
+	return "unknown"
+}
 
 var fontSet = []byte{
 	0xF0, 0x90, 0x90, 0x90, 0xF0, // 0
@@ -64,150 +110,267 @@ var fontSet = []byte{
 	0xF0, 0x80, 0xF0, 0x80, 0x80, // F
 }
 
+var bigFontSet = []byte{
+	0xFF, 0xFF, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, // 0
+	0x18, 0x78, 0x78, 0x18, 0x18, 0x18, 0x18, 0x18, 0xFF, 0xFF, // 1
+	0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, // 2
+	0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 3
+	0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0x03, 0x03, // 4
+	0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 5
+	0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, // 6
+	0xFF, 0xFF, 0x03, 0x03, 0x06, 0x0C, 0x18, 0x18, 0x18, 0x18, // 7
+	0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, // 8
+	0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 9
+	0x7E, 0xFF, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xC3, // A
+	0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC, // B
+	0x3C, 0xFF, 0xC3, 0xC0, 0xC0, 0xC0, 0xC0, 0xC3, 0xFF, 0x3C, // C
+	0xFC, 0xFE, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFE, 0xFC, // D
+	0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, // E
+	0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xC0, 0xC0, // F
+}
+
 type Processor struct {
-	memory          [4096]byte
-	v               [RegisterCount]byte
-	keyState        [KeyCount]atomic.Bool
-	display         [Area]byte
-	stack           [16]uint16
-	sp              uint8
-	pc              uint16
-	i               uint16
-	delay           uint8
-	sound           uint8
-	lastTimerUpdate time.Time
-}
-
-func (p *Processor) Execute(op Opcode, info *uint8) {
-	switch op.kind() {
-	case 0x0:
-		switch uint16(op) {
-		case 0x00E0:
-			p.clearScreen(info)
-		case 0x00EE:
-			p.returnFromSubroutine()
-		default:
-			panic("unknown 0x0 opcode")
-		}
-	case 0x1:
-		p.jumpToLocation(op.nnn())
-	case 0x2:
-		p.callSubroutine(op.nnn())
-	case 0x3:
-		p.stepIfXEqualsNN(op.x(), op.nn())
-	case 0x4:
-		p.stepIfXNotEqualsNN(op.x(), op.nn())
-	case 0x5:
-		p.stepIfXEqualsY(op.x(), op.y())
-	case 0x6:
-		p.setXToNN(op.x(), op.nn())
-	case 0x7:
-		p.addNNToX(op.x(), op.nn())
-	case 0x8:
-		switch op.n() {
-		case 0x0:
-			p.setXToY(op.x(), op.y())
-		case 0x1:
-			p.orXY(op.x(), op.y())
-		case 0x2:
-			p.andXY(op.x(), op.y())
-		case 0x3:
-			p.xorXY(op.x(), op.y())
-		case 0x4:
-			p.addXY(op.x(), op.y())
-		case 0x5:
-			p.subtractYFromX(op.x(), op.y())
-		case 0x6:
-			p.shiftRightX(op.x())
-		case 0x7:
-			p.subtractXFromY(op.x(), op.y())
-		case 0xE:
-			p.shiftLeftX(op.x())
-		default:
-			panic("unknown 0x8 opcode")
-		}
-	case 0x9:
-		p.stepIfXNotEqualsY(op.x(), op.y())
-	case 0xA:
-		p.setIToNNN(op.nnn())
-	case 0xB:
-		p.jumpWithOffset(op.nnn())
-	case 0xC:
-		p.setXToRandom(op.x(), op.nn())
-	case 0xD:
-		p.drawSprite(op.x(), op.y(), op.n(), info)
-	case 0xE:
-		switch op.nn() {
-		case 0x9E:
-			p.stepIfKeyDown(op.x())
-		case 0xA1:
-			p.stepIfKeyUp(op.x())
-		default:
-			panic("unknown 0xE opcode")
-		}
-	case 0xF:
-		switch op.nn() {
-		case 0x07:
-			p.setXToDelay(op.x())
-		case 0x0A:
-			p.pauseUntilKeyPressed(op.x())
-		case 0x15:
-			p.setDelayToX(op.x())
-		case 0x18:
-			p.setSoundToX(op.x())
-		case 0x1E:
-			p.setIToX(op.x())
-		case 0x29:
-			p.setIToSymbol(op.x())
-		case 0x33:
-			p.binaryCodedDecimal(op.x())
-		case 0x55:
-			p.setRegistersToMemory(op.x())
-		case 0x65:
-			p.setMemoryToRegisters(op.x())
-		default:
-			panic("unknown 0xF opcode")
-		}
-	default:
-		panic("unknown opcode")
+	memory        [ExtendedMemorySize]byte
+	v             [RegisterCount]byte
+	keyState      [KeyCount]atomic.Bool
+	display       [HiResArea]byte
+	hires         bool
+	halted        bool
+	flags         [FlagCount]byte
+	plane         uint8
+	pattern       [PatternSize]byte
+	patternLoaded bool
+	pitch         uint8
+	stack         [16]uint16
+	sp            uint8
+	pc            uint16
+	i             uint16
+	delay         uint8
+	sound         uint8
+	vblank        bool
+	quirks        Quirks
+	mode          Mode
+	seed          uint64
+	source        rand.Source
+	rng           rand.Source
+
+	// cycles counts the instructions executed since the last reset.
+	cycles uint64
+	tracer Tracer
+
+	// When tracking, or tracing, the memory accessed by the last
+	// instruction is recorded for debuggers and tracers.
+	tracking bool
+	accesses []Access
+
+	// When caching, instructions are kept decoded by address.
+	caching bool
+	cache   []decoded
+
+	// When recompiling, blocks of instructions are kept compiled by their
+	// starting address. In lockstep, an interpreting copy follows along.
+	// Blocks translated ahead of time, and those compiled before the last
+	// reset, are kept by their start, and go into the table when they are
+	// next run.
+	recompiling bool
+	lockstep    bool
+	blocks      []*block
+	translated  map[uint16]*block
+	compiled    map[uint16]*block
+	shadow      *Processor
+
+	// codeStart and codeEnd bound the memory compiled into blocks, which is
+	// empty while codeEnd is zero. longest is the most bytes a block there
+	// spans.
+	codeStart int
+	codeEnd   int
+	longest   int
+
+	// info collects the flags of the instruction being stepped. Keeping it
+	// here, rather than on the stack, saves an allocation per step, as the
+	// handlers are called through the instruction table.
+	info uint8
+}
+
+// Access describes a range of memory read or written by an instruction.
+type Access struct {
+	Address uint16
+	Size    int
+	Write   bool
+}
+
+type Option func(*Processor)
+
+func WithMode(m Mode) Option {
+	return func(p *Processor) {
+		p.mode = m
+	}
+}
+
+// WithSeed seeds the random number generator used by CXNN. Processors built
+// without a seed or source are seeded randomly.
+func WithSeed(seed uint64) Option {
+	return func(p *Processor) {
+		p.seed = seed
+		p.source = nil
+	}
+}
+
+// WithRandSource makes CXNN draw from src, rather than a generator seeded
+// by the processor. The source is not rewound by Reset.
+func WithRandSource(src rand.Source) Option {
+	return func(p *Processor) {
+		p.source = src
+	}
+}
+
+func WithQuirks(q Quirks) Option {
+	return func(p *Processor) {
+		p.quirks = q
+	}
+}
+
+// Engines maps the names accepted by frontends to the options that select
+// each way of running programs.
+var Engines = map[string][]Option{
+	"interpreter": nil,
+	"cached":      {WithDecodeCache()},
+	"recompiler":  {WithRecompiler()},
+	"lockstep":    {WithLockstep()},
+}
+
+func NewProcessor(opts ...Option) *Processor {
+	p := &Processor{quirks: DefaultQuirks, seed: rand.Uint64()}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.Reset()
+	return p
+}
+
+func (p *Processor) Execute(op Opcode, info *uint8) error {
+	in, ok := Decode(op)
+	if !ok || !p.supports(in.Mode) {
+		return ErrUnknownOpcode
 	}
+	return in.execute(p, op, info)
 }
 
 func (p *Processor) Reset() {
-	*p = Processor{}
+	// Quirks, mode and the random seed are chosen when the processor is built
+	// and survive a reset, as do the RPL user flags which programs use as
+	// persistent storage. Tracking, tracing, caching and recompiling are
+	// left to whoever enabled them.
+	cache, blocks := p.cache, p.blocks
+	codeStart, codeEnd := p.codeStart, p.codeEnd
+	*p = Processor{
+		quirks:   p.quirks,
+		mode:     p.mode,
+		flags:    p.flags,
+		seed:     p.seed,
+		source:   p.source,
+		tracer:   p.tracer,
+		tracking: p.tracking,
+		accesses: p.accesses[:0],
+		caching:  p.caching,
+		plane:    1,
+		pitch:    DefaultPitch,
+
+		recompiling: p.recompiling,
+		lockstep:    p.lockstep,
+		translated:  p.translated,
+		compiled:    p.compiled,
+		shadow:      p.shadow,
+	}
+
+	// Reseeding makes every run from a reset draw the same random numbers.
+	p.rng = p.source
+	if p.rng == nil {
+		p.rng = rand.NewPCG(p.seed, p.seed)
+	}
+
+	if p.caching {
+		if len(cache) == p.memorySize() {
+			clear(cache)
+		} else {
+			cache = make([]decoded, p.memorySize())
+		}
+		p.cache = cache
+	}
+
+	if p.recompiling || p.translated != nil {
+		if len(blocks) == p.memorySize() {
+			p.keepBlocks(blocks, codeStart, codeEnd)
+		} else {
+			blocks = make([]*block, p.memorySize())
+		}
+		p.blocks = blocks
+	}
 
 	written := p.Write(FontStartAddress, fontSet)
-	if int(written) < len(fontSet) {
+	if written < len(fontSet) {
 		panic("insufficient memory to write font set")
 	}
+
+	written = p.Write(BigFontStartAddress, bigFontSet)
+	if written < len(bigFontSet) {
+		panic("insufficient memory to write big font set")
+	}
 }
 
-func (p *Processor) Write(loc uint16, data []byte) uint16 {
-	var i uint16
-	for ; loc+i < 0xFFF && int(i) < len(data); i++ {
-		p.memory[loc+i] = data[i]
+func (p *Processor) Write(loc uint16, data []byte) int {
+	var i int
+	for ; int(loc)+i < p.memorySize() && i < len(data); i++ {
+		p.memory[int(loc)+i] = data[i]
 	}
+	p.invalidate(loc, i)
 	return i
 }
 
-func (p *Processor) Read(loc uint16, data []byte) uint16 {
-	var i uint16
-	for ; loc+i < 0xFFF && int(i) < len(data); i++ {
-		data[i] = p.memory[loc+i]
+func (p *Processor) Read(loc uint16, data []byte) int {
+	var i int
+	for ; int(loc)+i < p.memorySize() && i < len(data); i++ {
+		data[i] = p.memory[int(loc)+i]
 	}
 	return i
 }
 
-func (p *Processor) Display() []byte {
-	return p.display[:]
+func (p *Processor) memorySize() int {
+	return p.mode.memorySize()
 }
 
-func (p *Processor) Load(b []byte) {
-	written := p.Write(ProgramStartAddress, b)
-	if int(written) < len(b) {
-		panic("insufficient memory")
+// memorySize returns the size of the address space, which is extended to
+// 64 KiB on XO-CHIP.
+func (m Mode) memorySize() int {
+	if m >= ModeXOCHIP {
+		return ExtendedMemorySize
 	}
+	return MemorySize
+}
+
+// Display returns the pixels of the display in row-major order, along with
+// its current width and height. Each pixel holds one bit per bitplane.
+func (p *Processor) Display() ([]byte, int, int) {
+	width, height := p.resolution()
+	return p.display[:width*height], width, height
+}
+
+func (p *Processor) resolution() (int, int) {
+	if p.hires {
+		return HiResWidth, HiResHeight
+	}
+	return Width, Height
+}
+
+// Load writes a program at ProgramStartAddress. A program larger than the
+// memory after it is rejected before anything is written.
+func (p *Processor) Load(b []byte) error {
+	if int(ProgramStartAddress)+len(b) > p.memorySize() {
+		return ErrInsufficientMemory
+	}
+	p.Write(ProgramStartAddress, b)
 	p.pc = ProgramStartAddress
+	return nil
 }
 
 func (p *Processor) SetKey(key uint8, value bool) {
@@ -223,6 +386,16 @@ func (p *Processor) StackDepth() int {
 	return int(p.sp)
 }
 
+// Stack returns the return addresses on the stack, oldest first.
+func (p *Processor) Stack() []uint16 {
+	return append([]uint16{}, p.stack[:p.sp]...)
+}
+
+// Timers returns the values of the delay and sound timers.
+func (p *Processor) Timers() (delay, sound uint8) {
+	return p.delay, p.sound
+}
+
 func (p *Processor) Index() uint16 {
 	return p.i
 }
@@ -231,38 +404,87 @@ func (p *Processor) ProgramCounter() uint16 {
 	return p.pc
 }
 
-func (p *Processor) OpcodeAt(offset uint16) Opcode {
-	read := p.Read(offset, buffer[:])
-	if read < 2 {
-		panic("program runaway")
-	}
+func (p *Processor) Quirks() Quirks {
+	return p.quirks
+}
+
+func (p *Processor) Mode() Mode {
+	return p.mode
+}
+
+func (p *Processor) Seed() uint64 {
+	return p.seed
+}
+
+// Cycles returns the number of instructions executed since the last reset.
+func (p *Processor) Cycles() uint64 {
+	return p.cycles
+}
 
+func (p *Processor) Halted() bool {
+	return p.halted
+}
+
+// AudioPattern returns the XO-CHIP audio pattern and pitch. The pattern is
+// only meaningful once a program has loaded one, as reported by ok.
+func (p *Processor) AudioPattern() (pattern [PatternSize]byte, pitch uint8, ok bool) {
+	return p.pattern, p.pitch, p.patternLoaded
+}
+
+func (p *Processor) supports(m Mode) bool {
+	return p.mode >= m
+}
+
+func (p *Processor) OpcodeAt(offset uint16) (Opcode, error) {
 	// opcode is a 16bit value, comprised of two contiguous 8bit values
 	// in memory, starting at the program counter
-	high := uint16(buffer[0]) // high-order bits of opcode
-	low := uint16(buffer[1])  // low-order bits of opcode
-	return Opcode((high << 8) | low)
+	if int(offset)+2 > p.memorySize() {
+		return 0, ErrProgramRunaway
+	}
+	return Opcode(uint16(p.memory[offset])<<8 | uint16(p.memory[offset+1])), nil
 }
 
-func (p *Processor) Step() uint8 {
-	var info uint8
+func (p *Processor) Step() (uint8, error) {
+	if p.halted {
+		return Halt, nil
+	}
+
+	p.accesses = p.accesses[:0]
 
-	opcode := p.OpcodeAt(p.ProgramCounter())
+	pc := p.ProgramCounter()
+
+	opcode, in, err := p.fetch(pc)
+	if err != nil {
+		return 0, &Fault{PC: pc, Err: err}
+	}
 
 	p.pc += 2
 
-	p.Execute(opcode, &info)
+	p.info = 0
+	err = ErrUnknownOpcode
+	if in != nil {
+		err = in.execute(p, opcode, &p.info)
+	}
+	if err != nil {
+		// Faulting instructions leave no side effects, so rewinding the
+		// program counter leaves the machine ready for inspection.
+		p.pc = pc
+		return 0, &Fault{PC: pc, Opcode: opcode, Err: err}
+	}
 
-	if time.Since(p.lastTimerUpdate) >= TimerRate {
-		if p.sound > 0 {
-			p.sound--
-		}
+	if p.info&Stall != 0 {
+		return p.info | p.timerInfo(), nil
+	}
 
-		if p.delay > 0 {
-			p.delay--
-		}
-		p.lastTimerUpdate = time.Now()
+	p.cycles++
+	if p.tracer != nil {
+		p.trace(pc, opcode)
 	}
+	return p.info | p.timerInfo(), nil
+}
+
+func (p *Processor) timerInfo() uint8 {
+	var info uint8
 
 	if p.sound > 0 {
 		info |= Sound
@@ -273,3 +495,81 @@ func (p *Processor) Step() uint8 {
 	}
 	return info
 }
+
+// TickTimers advances the delay and sound timers by one tick. The host calls
+// it at TimerRate, 60 times per emulated second, which also marks the vertical
+// blank that the DisplayWait quirk waits for.
+func (p *Processor) TickTimers() {
+	if p.sound > 0 {
+		p.sound--
+	}
+
+	if p.delay > 0 {
+		p.delay--
+	}
+	p.vblank = true
+}
+
+// Run executes up to n instructions, stopping early when the processor
+// faults or halts, or stalls waiting for the vertical blank or a key, which
+// it would do until the next frame. The returned info combines the flags of
+// every executed instruction.
+func (p *Processor) Run(n int) (uint8, error) {
+	if p.lockstep {
+		if err := p.sync(); err != nil {
+			return 0, err
+		}
+	}
+
+	// Blocks add their flags to those of the instructions before them.
+	var info uint8
+	p.info = 0
+
+	for executed := 0; executed < n; {
+		var stepInfo uint8
+		var err error
+
+		if b := p.blockAt(p.pc); b != nil {
+			var count int
+			stepInfo, count, err = p.runBlock(b, n-executed)
+			executed += count
+		} else {
+			pc := p.pc
+			stepInfo, err = p.Step()
+			executed++
+
+			if p.shadow != nil {
+				count := 1
+				if err != nil {
+					count = 0
+				}
+				err = p.follow(pc, count, err)
+			}
+		}
+
+		info |= stepInfo
+		if err != nil {
+			return info, err
+		}
+
+		if stepInfo&(Halt|Stall) != 0 {
+			break
+		}
+	}
+	return info | p.timerInfo(), nil
+}
+
+// RunFrame executes up to n instructions, followed by a single timer tick.
+// The returned info combines the flags of every executed instruction. The
+// frame ends early, without a timer tick, when the processor faults.
+func (p *Processor) RunFrame(n int) (uint8, error) {
+	info, err := p.Run(n)
+	if err != nil {
+		return info, err
+	}
+
+	p.TickTimers()
+
+	// The timer flags reflect the timers after the tick.
+	return (info &^ (Sound | Delay)) | p.timerInfo(), nil
+}
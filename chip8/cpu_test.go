@@ -0,0 +1,75 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLoadTooLarge(t *testing.T) {
+	p := NewProcessor()
+	rom := bytes.Repeat([]byte{0xAA}, MemorySize)
+
+	if err := p.Load(rom); !errors.Is(err, ErrInsufficientMemory) {
+		t.Fatalf("Load() = %v, want %v", err, ErrInsufficientMemory)
+	}
+
+	mem := make([]byte, MemorySize-int(ProgramStartAddress))
+	p.Read(ProgramStartAddress, mem)
+	if !bytes.Equal(mem, make([]byte, len(mem))) {
+		t.Error("Load() wrote memory before failing")
+	}
+}
+
+func TestLoadFits(t *testing.T) {
+	p := NewProcessor()
+	rom := bytes.Repeat([]byte{0xAA}, MemorySize-int(ProgramStartAddress))
+
+	if err := p.Load(rom); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if pc := p.ProgramCounter(); pc != ProgramStartAddress {
+		t.Errorf("ProgramCounter() = %03X, want %03X", pc, ProgramStartAddress)
+	}
+}
+
+// constant is a random source that always draws the same number.
+type constant uint64
+
+func (c constant) Uint64() uint64 { return uint64(c) }
+
+// TestLockstepSteps checks that lockstep compares the instructions stepped
+// outside of blocks, as they all are with a tracer. The copy draws from its
+// seed rather than the constant source, so the two disagree on V0.
+func TestLockstepSteps(t *testing.T) {
+	var traced records
+	p := NewProcessor(WithLockstep(), WithTracer(&traced), WithSeed(1), WithRandSource(constant(0)))
+	if err := p.Load([]byte{0xC0, 0xFF, 0x12, 0x00}); err != nil { // RND V0, FF; JP 200
+		t.Fatal(err)
+	}
+
+	_, err := p.Run(10)
+	var divergence *Divergence
+	if !errors.As(err, &divergence) || divergence.Field != "V0" {
+		t.Fatalf("Run() = %v, want a divergence on V0", err)
+	}
+	if len(traced) != 1 {
+		t.Errorf("Run() went on for %d instructions after the divergence", len(traced)-1)
+	}
+}
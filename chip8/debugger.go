@@ -0,0 +1,681 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrBadBreakpoint = errors.New("malformed breakpoint")
+
+type BreakpointKind uint8
+
+const (
+	BreakAtPC        BreakpointKind = iota // Before the instruction at Address runs.
+	BreakOnCondition                       // When Condition becomes true.
+	BreakOnRead                            // After memory in Address-End is read.
+	BreakOnWrite                           // After memory in Address-End is written.
+	BreakOnAccess                          // After memory in Address-End is read or written.
+	BreakOnIndex                           // After I changes.
+	BreakOnOpcode                          // Before an instruction matching Pattern runs.
+)
+
+var breakpointKinds = []string{
+	BreakAtPC:        "pc",
+	BreakOnCondition: "if",
+	BreakOnRead:      "read",
+	BreakOnWrite:     "write",
+	BreakOnAccess:    "access",
+	BreakOnIndex:     "index",
+	BreakOnOpcode:    "op",
+}
+
+type Breakpoint struct {
+	ID        int
+	Kind      BreakpointKind
+	Address   uint16
+	End       uint16 // Last address watched, inclusive.
+	Condition Condition
+	Pattern   OpcodePattern
+
+	// active holds the last value of the condition, so that a condition
+	// breakpoint only stops execution as it becomes true.
+	active bool
+	primed bool
+}
+
+// ParseBreakpoint reads a breakpoint in the form written by its String
+// method. The kind is followed by its argument:
+//
+//	pc 2A0
+//	if V3 == 10
+//	read 300-30F
+//	write 300
+//	access 300-3FF
+//	index
+//	op DXYN
+func ParseBreakpoint(spec string) (Breakpoint, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(spec), " ")
+	arg = strings.TrimSpace(arg)
+
+	var bp Breakpoint
+	switch strings.ToLower(kind) {
+	case "pc":
+		addr, err := parseAddress(arg)
+		if err != nil {
+			return bp, err
+		}
+		bp.Kind, bp.Address = BreakAtPC, addr
+	case "if":
+		cond, err := ParseCondition(arg)
+		if err != nil {
+			return bp, err
+		}
+		bp.Kind, bp.Condition = BreakOnCondition, cond
+	case "read", "write", "access":
+		start, end, isRange := strings.Cut(arg, "-")
+		first, err := parseAddress(start)
+		if err != nil {
+			return bp, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseAddress(end); err != nil {
+				return bp, err
+			}
+		}
+		if last < first {
+			return bp, ErrBadBreakpoint
+		}
+		bp.Address, bp.End = first, last
+
+		switch strings.ToLower(kind) {
+		case "read":
+			bp.Kind = BreakOnRead
+		case "write":
+			bp.Kind = BreakOnWrite
+		default:
+			bp.Kind = BreakOnAccess
+		}
+	case "index":
+		bp.Kind = BreakOnIndex
+	case "op":
+		pattern, err := ParseOpcodePattern(arg)
+		if err != nil {
+			return bp, err
+		}
+		bp.Kind, bp.Pattern = BreakOnOpcode, pattern
+	default:
+		return bp, ErrBadBreakpoint
+	}
+	return bp, nil
+}
+
+func (b Breakpoint) String() string {
+	str := breakpointKinds[b.Kind]
+
+	switch b.Kind {
+	case BreakAtPC:
+		str += " " + FormatAddress(b.Address)
+	case BreakOnCondition:
+		str += " " + b.Condition.String()
+	case BreakOnRead, BreakOnWrite, BreakOnAccess:
+		str += " " + FormatAddress(b.Address)
+		if b.End != b.Address {
+			str += "-" + FormatAddress(b.End)
+		}
+	case BreakOnOpcode:
+		str += " " + b.Pattern.String()
+	}
+	return str
+}
+
+func (b Breakpoint) watches(a Access) bool {
+	switch {
+	case b.Kind == BreakOnRead && a.Write,
+		b.Kind == BreakOnWrite && !a.Write:
+		return false
+	}
+
+	last := int(a.Address) + a.Size - 1
+	return int(a.Address) <= int(b.End) && last >= int(b.Address)
+}
+
+type StopReason uint8
+
+const (
+	StopBreakpoint StopReason = iota // A breakpoint was hit.
+	StopStep                         // A step over or step out completed.
+)
+
+// Stop describes why the debugger stopped execution.
+type Stop struct {
+	Reason     StopReason
+	Breakpoint Breakpoint // The breakpoint that was hit.
+	Access     Access     // The access that hit a memory breakpoint.
+	PC         uint16     // The program counter once execution stopped.
+}
+
+func (s *Stop) String() string {
+	if s.Reason == StopStep {
+		return "step complete at " + FormatAddress(s.PC)
+	}
+	return "breakpoint " + strconv.Itoa(s.Breakpoint.ID) + " (" + s.Breakpoint.String() + ") at " + FormatAddress(s.PC)
+}
+
+type targetKind uint8
+
+const (
+	targetNone   targetKind = iota
+	targetOver              // Requested, resolved to targetStep or targetReturn.
+	targetStep              // Stop after the next instruction.
+	targetReturn            // Stop once back at pc with the stack at depth.
+	targetOut               // Requested, resolved to targetDepth.
+	targetDepth             // Stop once the stack is shallower than depth.
+)
+
+// Debugger wraps a Processor with breakpoints and stepping. It is safe to
+// manage breakpoints and request steps from one goroutine while another
+// runs the processor through the debugger. Nothing but the running
+// goroutine looks at the processor.
+type Debugger struct {
+	mu          sync.Mutex
+	p           *Processor
+	breakpoints []Breakpoint
+	nextID      int
+
+	// skip lets execution resume from an instruction that stopped on a
+	// breakpoint, rather than stopping on it again.
+	skip bool
+
+	target      targetKind
+	targetPC    uint16
+	targetDepth uint8
+}
+
+func NewDebugger(p *Processor) *Debugger {
+	p.tracking = true
+	return &Debugger{p: p, nextID: 1}
+}
+
+func (d *Debugger) Processor() *Processor {
+	return d.p
+}
+
+// Add installs a breakpoint, returning its ID.
+func (d *Debugger) Add(bp Breakpoint) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	bp.ID = d.nextID
+	d.nextID++
+	bp.primed = false
+
+	d.breakpoints = append(d.breakpoints, bp)
+	return bp.ID
+}
+
+func (d *Debugger) Remove(id int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, bp := range d.breakpoints {
+		if bp.ID == id {
+			d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// StepOver arranges for execution to stop after the next instruction. When
+// that instruction is a CALL, the whole subroutine runs before stopping.
+func (d *Debugger) StepOver() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.target = targetOver
+}
+
+// StepOut arranges for execution to stop once the current subroutine
+// returns. Outside of a subroutine, it stops after the next instruction.
+func (d *Debugger) StepOut() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.target = targetOut
+}
+
+// resolve turns a step request into a target, from the state of the
+// processor when it next runs.
+func (d *Debugger) resolve() {
+	switch d.target {
+	case targetOver:
+		d.target = targetStep
+		if op, err := d.p.OpcodeAt(d.p.pc); err == nil && isCall(op) {
+			d.target, d.targetPC, d.targetDepth = targetReturn, d.p.pc+2, d.p.sp
+		}
+	case targetOut:
+		d.target = targetStep
+		if d.p.sp > 0 {
+			d.target, d.targetDepth = targetDepth, d.p.sp
+		}
+	}
+
+	for i := range d.breakpoints {
+		if bp := &d.breakpoints[i]; bp.Kind == BreakOnCondition && !bp.primed {
+			bp.active = bp.Condition.Eval(d.p)
+			bp.primed = true
+		}
+	}
+}
+
+func isCall(op Opcode) bool {
+	in, ok := Decode(op)
+	return ok && in.Flow == FlowCall
+}
+
+// Check reports a breakpoint on the instruction about to run. Once it has
+// reported a stop, the next call lets the instruction run.
+func (d *Debugger) Check() *Stop {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.check()
+}
+
+func (d *Debugger) check() *Stop {
+	if d.skip {
+		return nil
+	}
+
+	for _, bp := range d.breakpoints {
+		var hit bool
+		switch bp.Kind {
+		case BreakAtPC:
+			hit = d.p.pc == bp.Address
+		case BreakOnOpcode:
+			op, err := d.p.OpcodeAt(d.p.pc)
+			hit = err == nil && bp.Pattern.Matches(op)
+		}
+
+		if hit {
+			d.skip = true
+			d.target = targetNone
+			return &Stop{Reason: StopBreakpoint, Breakpoint: bp, PC: d.p.pc}
+		}
+	}
+	return nil
+}
+
+// Step runs a single instruction, ignoring breakpoints on it, and reports
+// any breakpoint or step target reached by running it.
+func (d *Debugger) Step() (uint8, *Stop, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.step()
+}
+
+func (d *Debugger) step() (uint8, *Stop, error) {
+	d.resolve()
+
+	index := d.p.i
+
+	info, err := d.p.Step()
+	d.skip = false
+	if err != nil {
+		d.target = targetNone
+		return info, nil, err
+	}
+
+	stop := d.watch(index)
+	if stop == nil && d.reached() {
+		stop = &Stop{Reason: StopStep, PC: d.p.pc}
+	}
+
+	if stop != nil {
+		d.target = targetNone
+	}
+	return info, stop, nil
+}
+
+// watch checks the breakpoints that observe the effects of an instruction.
+func (d *Debugger) watch(index uint16) *Stop {
+	var stop *Stop
+
+	for i := range d.breakpoints {
+		bp := &d.breakpoints[i]
+
+		switch bp.Kind {
+		case BreakOnCondition:
+			// Every condition is evaluated, so that each one tracks its
+			// value even when another breakpoint stops first.
+			was := bp.active
+			bp.active = bp.Condition.Eval(d.p)
+			if bp.active && !was && stop == nil {
+				stop = &Stop{Reason: StopBreakpoint, Breakpoint: *bp, PC: d.p.pc}
+			}
+		case BreakOnRead, BreakOnWrite, BreakOnAccess:
+			for _, a := range d.p.accesses {
+				if bp.watches(a) && stop == nil {
+					stop = &Stop{Reason: StopBreakpoint, Breakpoint: *bp, Access: a, PC: d.p.pc}
+				}
+			}
+		case BreakOnIndex:
+			if d.p.i != index && stop == nil {
+				stop = &Stop{Reason: StopBreakpoint, Breakpoint: *bp, PC: d.p.pc}
+			}
+		}
+	}
+	return stop
+}
+
+func (d *Debugger) reached() bool {
+	switch d.target {
+	case targetStep:
+		return true
+	case targetReturn:
+		return d.p.pc == d.targetPC && d.p.sp == d.targetDepth
+	case targetDepth:
+		return d.p.sp < d.targetDepth
+	}
+	return false
+}
+
+// Active reports whether breakpoints or a step target are set, which make
+// Run execute one instruction at a time.
+func (d *Debugger) Active() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.active()
+}
+
+func (d *Debugger) active() bool {
+	return len(d.breakpoints) > 0 || d.target != targetNone
+}
+
+// Run executes up to n instructions, stopping early at a breakpoint, a
+// step target, a fault or when the processor halts. While the debugger is
+// not active, the processor runs on its own, which lets a recompiling
+// processor run whole blocks.
+func (d *Debugger) Run(n int) (uint8, *Stop, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.active() {
+		info, err := d.alone(d.p.Run, n)
+		return info, nil, err
+	}
+	return d.run(n)
+}
+
+// RunFrame is the debugger's counterpart to Processor.RunFrame, which it
+// calls while not active. The timers are only ticked when the whole frame
+// runs.
+func (d *Debugger) RunFrame(n int) (uint8, *Stop, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.active() {
+		info, err := d.alone(d.p.RunFrame, n)
+		return info, nil, err
+	}
+
+	info, stop, err := d.run(n)
+	if stop != nil || err != nil {
+		return info, stop, err
+	}
+
+	d.p.TickTimers()
+	return (info &^ (Sound | Delay)) | d.p.timerInfo(), nil, nil
+}
+
+// alone lets the processor run n instructions on its own with run.
+func (d *Debugger) alone(run func(n int) (uint8, error), n int) (uint8, error) {
+	// Without watchpoints, the accesses of the instructions need not be
+	// recorded.
+	d.skip = false
+	d.p.tracking = false
+	info, err := run(n)
+	d.p.tracking = true
+	return info, err
+}
+
+// run steps through up to n instructions, checking the breakpoints and the
+// step target before each.
+func (d *Debugger) run(n int) (uint8, *Stop, error) {
+	var info uint8
+
+	for range n {
+		if stop := d.check(); stop != nil {
+			return info, stop, nil
+		}
+
+		stepInfo, stop, err := d.step()
+		info |= stepInfo
+		if err != nil || stop != nil {
+			return info, stop, err
+		}
+
+		if stepInfo&Halt != 0 {
+			break
+		}
+	}
+	return info, nil, nil
+}
+
+// Condition compares two operands, each of which is a register (V0-VF, I,
+// PC, SP, DT or ST), a byte of memory ([2A0] or [I]) or a hexadecimal
+// number.
+type Condition struct {
+	left, right operand
+	op          string
+}
+
+var comparisons = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func ParseCondition(s string) (Condition, error) {
+	for _, op := range comparisons {
+		left, right, ok := strings.Cut(s, op)
+		if !ok {
+			continue
+		}
+
+		l, err := parseOperand(left)
+		if err != nil {
+			return Condition{}, err
+		}
+
+		r, err := parseOperand(right)
+		if err != nil {
+			return Condition{}, err
+		}
+		return Condition{left: l, right: r, op: op}, nil
+	}
+	return Condition{}, ErrBadBreakpoint
+}
+
+func (c Condition) Eval(p *Processor) bool {
+	l, r := c.left.eval(p), c.right.eval(p)
+
+	switch c.op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "<=":
+		return l <= r
+	case ">=":
+		return l >= r
+	case "<":
+		return l < r
+	case ">":
+		return l > r
+	}
+	return false
+}
+
+func (c Condition) String() string {
+	return c.left.String() + " " + c.op + " " + c.right.String()
+}
+
+type operandKind uint8
+
+const (
+	operandNumber operandKind = iota
+	operandV
+	operandI
+	operandPC
+	operandSP
+	operandDT
+	operandST
+	operandMemory
+	operandMemoryAtI
+)
+
+type operand struct {
+	kind  operandKind
+	value uint16 // The number, register or address.
+}
+
+var namedOperands = map[string]operandKind{
+	"I":   operandI,
+	"PC":  operandPC,
+	"SP":  operandSP,
+	"DT":  operandDT,
+	"ST":  operandST,
+	"[I]": operandMemoryAtI,
+}
+
+func parseOperand(s string) (operand, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+
+	if kind, ok := namedOperands[s]; ok {
+		return operand{kind: kind}, nil
+	}
+
+	if len(s) == 2 && s[0] == 'V' {
+		if x, err := strconv.ParseUint(s[1:], 16, 4); err == nil {
+			return operand{kind: operandV, value: uint16(x)}, nil
+		}
+	}
+
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		addr, err := parseAddress(s[1 : len(s)-1])
+		return operand{kind: operandMemory, value: addr}, err
+	}
+
+	n, err := parseAddress(s)
+	return operand{kind: operandNumber, value: n}, err
+}
+
+func (o operand) eval(p *Processor) int {
+	switch o.kind {
+	case operandV:
+		return int(p.v[o.value])
+	case operandI:
+		return int(p.i)
+	case operandPC:
+		return int(p.pc)
+	case operandSP:
+		return int(p.sp)
+	case operandDT:
+		return int(p.delay)
+	case operandST:
+		return int(p.sound)
+	case operandMemory:
+		return int(p.memory[o.value])
+	case operandMemoryAtI:
+		return int(p.memory[p.i])
+	}
+	return int(o.value)
+}
+
+func (o operand) String() string {
+	switch o.kind {
+	case operandV:
+		return "V" + u8toh(uint8(o.value), 1)
+	case operandMemory:
+		return "[" + FormatAddress(o.value) + "]"
+	case operandNumber:
+		if o.value > 0xFF {
+			return FormatAddress(o.value)
+		}
+		return u16toh(o.value, 2)
+	}
+
+	for name, kind := range namedOperands {
+		if kind == o.kind {
+			return name
+		}
+	}
+	return ""
+}
+
+// OpcodePattern matches opcodes written as four hexadecimal digits, where
+// any other character, such as the X, Y and N of 8XY6 or DXYN, matches any
+// digit.
+type OpcodePattern struct {
+	Value Opcode
+	Mask  Opcode
+}
+
+func ParseOpcodePattern(s string) (OpcodePattern, error) {
+	if len(s) != 4 {
+		return OpcodePattern{}, ErrBadBreakpoint
+	}
+
+	var pattern OpcodePattern
+	for _, c := range strings.ToUpper(s) {
+		pattern.Value <<= 4
+		pattern.Mask <<= 4
+
+		if digit, err := strconv.ParseUint(string(c), 16, 4); err == nil {
+			pattern.Value |= Opcode(digit)
+			pattern.Mask |= 0xF
+		}
+	}
+	return pattern, nil
+}
+
+func (o OpcodePattern) Matches(op Opcode) bool {
+	return op&o.Mask == o.Value
+}
+
+func (o OpcodePattern) String() string {
+	var b strings.Builder
+	for shift := 12; shift >= 0; shift -= 4 {
+		if (o.Mask>>shift)&0xF == 0 {
+			b.WriteByte('?')
+			continue
+		}
+		b.WriteString(u8toh(uint8(o.Value>>shift)&0xF, 1))
+	}
+	return b.String()
+}
+
+// parseAddress reads a hexadecimal number, with or without a 0x prefix.
+func parseAddress(s string) (uint16, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+
+	n, err := strconv.ParseUint(s, 16, 16)
+	if err != nil {
+		return 0, ErrBadBreakpoint
+	}
+	return uint16(n), nil
+}
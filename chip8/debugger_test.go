@@ -0,0 +1,99 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import "testing"
+
+// straight is a run of loads that a recompiler compiles into one block,
+// followed by a jump to itself.
+var straight = []byte{
+	0x60, 0x01, // 200: LD V0, 01
+	0x61, 0x02, // 202: LD V1, 02
+	0x62, 0x03, // 204: LD V2, 03
+	0x12, 0x06, // 206: JP 206
+}
+
+func TestDebuggerRun(t *testing.T) {
+	for name, opts := range Engines {
+		t.Run(name, func(t *testing.T) {
+			p := NewProcessor(opts...)
+			if err := p.Load(straight); err != nil {
+				t.Fatal(err)
+			}
+			d := NewDebugger(p)
+
+			// A breakpoint inside a block stops the run on it.
+			id := d.Add(Breakpoint{Kind: BreakAtPC, Address: 0x204})
+			_, stop, err := d.Run(100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stop == nil || stop.PC != 0x204 {
+				t.Fatalf("Run() stopped at %v, want 204", stop)
+			}
+			if v1, v2 := p.Register(1), p.Register(2); v1 != 2 || v2 != 0 {
+				t.Errorf("V1, V2 = %d, %d, want 2, 0", v1, v2)
+			}
+
+			// Without breakpoints the processor runs on its own.
+			d.Remove(id)
+			if d.Active() {
+				t.Error("Active() with no breakpoints or step target")
+			}
+			_, stop, err = d.Run(100)
+			if err != nil || stop != nil {
+				t.Fatalf("Run() = %v, %v", stop, err)
+			}
+			if v2 := p.Register(2); v2 != 3 {
+				t.Errorf("V2 = %d, want 3", v2)
+			}
+
+			// A step target steps again.
+			d.StepOver()
+			if !d.Active() {
+				t.Error("not Active() after StepOver")
+			}
+			cycles := p.Cycles()
+			_, stop, err = d.Run(100)
+			if err != nil || stop == nil || stop.Reason != StopStep {
+				t.Fatalf("Run() = %v, %v, want a step stop", stop, err)
+			}
+			if n := p.Cycles() - cycles; n != 1 {
+				t.Errorf("Run() stepped %d instructions, want 1", n)
+			}
+		})
+	}
+}
+
+func TestDebuggerRunFrameTicks(t *testing.T) {
+	p := NewProcessor(WithRecompiler())
+	if err := p.Load([]byte{0x60, 0x05, 0xF0, 0x15, 0x12, 0x04}); err != nil { // LD V0, 05; LD DT, V0; JP 204
+		t.Fatal(err)
+	}
+	d := NewDebugger(p)
+
+	info, stop, err := d.RunFrame(DefaultCyclesPerFrame)
+	if err != nil || stop != nil {
+		t.Fatalf("RunFrame() = %v, %v", stop, err)
+	}
+	if delay, _ := p.Timers(); delay != 4 {
+		t.Errorf("delay = %d, want 4", delay)
+	}
+	if info&Delay == 0 {
+		t.Error("RunFrame() info lacks Delay")
+	}
+}
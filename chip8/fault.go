@@ -0,0 +1,45 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import "errors"
+
+var (
+	ErrUnknownOpcode      = errors.New("unknown opcode")
+	ErrStackOverflow      = errors.New("stack overflow")
+	ErrStackUnderflow     = errors.New("stack underflow")
+	ErrProgramRunaway     = errors.New("program runaway")
+	ErrInsufficientMemory = errors.New("insufficient memory")
+	ErrAddressOutOfRange  = errors.New("address out of range")
+)
+
+// Fault describes an instruction that could not be executed. The processor
+// is left as it was before the instruction was fetched, with the program
+// counter pointing at the faulting opcode.
+type Fault struct {
+	PC     uint16
+	Opcode Opcode
+	Err    error
+}
+
+func (f *Fault) Error() string {
+	return "fault at " + FormatAddress(f.PC) + " (opcode " + u16toh(uint16(f.Opcode), 4) + "): " + f.Err.Error()
+}
+
+func (f *Fault) Unwrap() error {
+	return f.Err
+}
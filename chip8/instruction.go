@@ -0,0 +1,663 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+// OperandKind describes an operand of an instruction: where its value is
+// encoded, and how it is written.
+type OperandKind uint8
+
+const (
+	OperandVX         OperandKind = iota // A register in the X nibble.
+	OperandVY                            // A register in the Y nibble.
+	OperandOptionalVY                    // VY, left unwritten when it is V0.
+	OperandRange                         // VX-VY.
+	OperandNibble                        // N, written with one digit.
+	OperandHeight                        // N, the height of a sprite, written with two digits.
+	OperandPlanes                        // The X nibble as a number.
+	OperandByte                          // NN.
+	OperandAddress                       // NNN.
+	OperandLong                          // The address in the word after the opcode.
+	OperandV0                            // V0, which is not encoded.
+	OperandI
+	OperandIndirect // [I]
+	OperandDelay    // DT
+	OperandSound    // ST
+	OperandKey      // K
+	OperandFont     // F
+	OperandBigFont  // HF
+	OperandBCD      // B
+	OperandFlags    // R
+)
+
+var keywords = []string{
+	OperandLong:     "LONG",
+	OperandV0:       "V0",
+	OperandI:        "I",
+	OperandIndirect: "[I]",
+	OperandDelay:    "DT",
+	OperandSound:    "ST",
+	OperandKey:      "K",
+	OperandFont:     "F",
+	OperandBigFont:  "HF",
+	OperandBCD:      "B",
+	OperandFlags:    "R",
+}
+
+// Keyword returns the word that stands for an operand with no value, such
+// as DT, or an empty string for an operand with a value.
+func (k OperandKind) Keyword() string {
+	if int(k) < len(keywords) {
+		return keywords[k]
+	}
+	return ""
+}
+
+// Field returns where the value of an operand is encoded in the opcode. A
+// range is encoded as X << 4 | Y. Operands without a value in the opcode
+// have an empty mask.
+func (k OperandKind) Field() (shift uint, mask Opcode) {
+	switch k {
+	case OperandVX, OperandPlanes:
+		return 8, 0x0F00
+	case OperandVY, OperandOptionalVY:
+		return 4, 0x00F0
+	case OperandRange:
+		return 4, 0x0FF0
+	case OperandNibble, OperandHeight:
+		return 0, 0x000F
+	case OperandByte:
+		return 0, 0x00FF
+	case OperandAddress:
+		return 0, 0x0FFF
+	}
+	return 0, 0
+}
+
+// Use is a set of the state an instruction reads or writes. The registers
+// are named relative to the operands of the instruction.
+type Use uint32
+
+const (
+	UseVX            Use = 1 << iota
+	UseVY                // Also read by the shifts under ShiftUsesVY.
+	UseV0                // Read by BNNN.
+	UseVF                // The flag register.
+	UseRange             // VX through VY.
+	UseThroughVX         // V0 through VX.
+	UseI                 // The index register.
+	UseMemory            // Memory at I.
+	UseStack             // The stack and stack pointer.
+	UseDelay             // The delay timer.
+	UseSound             // The sound timer.
+	UseKeys              // The keypad.
+	UseDisplay           // The display and its resolution.
+	UsePlanes            // The selected bitplanes.
+	UseAudio             // The audio pattern and pitch.
+	UseFlagRegisters     // The registers saved by FX75.
+)
+
+// FlagEffect describes what an instruction leaves in VF.
+type FlagEffect uint8
+
+const (
+	FlagNone      FlagEffect = iota
+	FlagCarry                // The carry, borrow or bit shifted out.
+	FlagCollision            // Whether a sprite erased a pixel.
+	FlagReset                // Cleared under the VFReset quirk.
+)
+
+// Flow describes where execution continues after an instruction.
+type Flow uint8
+
+const (
+	FlowNext         Flow = iota // The next instruction.
+	FlowSkip                     // The next instruction, or the one after it.
+	FlowJump                     // Its address operand.
+	FlowJumpIndirect             // Its address plus a register.
+	FlowCall                     // Its address operand, returning after it.
+	FlowReturn                   // The address on the stack.
+	FlowHalt                     // Nowhere; the program exits.
+)
+
+// Instruction describes an instruction: how it is decoded, written and
+// executed.
+type Instruction struct {
+	Pattern  Opcode // The bits of the opcode that identify the instruction,
+	Mask     Opcode // selected by Mask.
+	Mnemonic string
+	Operands []OperandKind
+	Mode     Mode // The least mode that supports the instruction.
+	Size     int  // In bytes, including any word after the opcode.
+
+	// Reads and Writes cover every quirk, so they may name more than a
+	// particular configuration uses.
+	Reads  Use
+	Writes Use
+	Flag   FlagEffect
+	Flow   Flow
+
+	// Cycles is the nominal cost in COSMAC VIP machine cycles, not counting
+	// time that depends on data. Instructions the VIP lacks cost as much
+	// as the nearest original instruction.
+	Cycles int
+
+	execute func(p *Processor, op Opcode, info *uint8) error
+}
+
+// Ignored returns the bits of an opcode that neither identify the
+// instruction nor encode an operand. The interpreter ignores them, but they
+// cannot be written in the instruction's mnemonic.
+func (in *Instruction) Ignored() Opcode {
+	used := in.Mask
+	for _, k := range in.Operands {
+		_, mask := k.Field()
+		used |= mask
+	}
+	return ^used
+}
+
+// Registers returns the V registers the instruction reads and writes in op,
+// as bit masks indexed by register.
+func (in *Instruction) Registers(op Opcode) (read, written uint16) {
+	return in.Reads.registers(op), in.Writes.registers(op)
+}
+
+func (u Use) registers(op Opcode) uint16 {
+	var mask uint16
+	if u&UseVX != 0 {
+		mask |= 1 << op.x()
+	}
+	if u&UseVY != 0 {
+		mask |= 1 << op.y()
+	}
+	if u&UseV0 != 0 {
+		mask |= 1
+	}
+	if u&UseVF != 0 {
+		mask |= 1 << CarryFlag
+	}
+	if u&UseRange != 0 {
+		lo, hi := min(op.x(), op.y()), max(op.x(), op.y())
+		for r := lo; r <= hi; r++ {
+			mask |= 1 << r
+		}
+	}
+	if u&UseThroughVX != 0 {
+		mask |= 1<<(op.x()+1) - 1
+	}
+	return mask
+}
+
+// Instructions lists every instruction of every mode.
+var Instructions = []Instruction{
+	{
+		Pattern: 0x00E0, Mask: 0xFFFF, Mnemonic: "CLS", Mode: ModeCHIP8, Size: 2,
+		Reads: UsePlanes, Writes: UseDisplay, Cycles: 24,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.clearScreen(info)
+			return nil
+		},
+	},
+	{
+		Pattern: 0x00EE, Mask: 0xFFFF, Mnemonic: "RET", Mode: ModeCHIP8, Size: 2,
+		Reads: UseStack, Writes: UseStack, Flow: FlowReturn, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.returnFromSubroutine()
+		},
+	},
+	{
+		Pattern: 0x00C0, Mask: 0xFFF0, Mnemonic: "SCD", Operands: []OperandKind{OperandNibble}, Mode: ModeSCHIP, Size: 2,
+		Reads: UseDisplay | UsePlanes, Writes: UseDisplay, Cycles: 24,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.scrollDown(op.n(), info)
+			return nil
+		},
+	},
+	{
+		Pattern: 0x00D0, Mask: 0xFFF0, Mnemonic: "SCU", Operands: []OperandKind{OperandNibble}, Mode: ModeXOCHIP, Size: 2,
+		Reads: UseDisplay | UsePlanes, Writes: UseDisplay, Cycles: 24,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.scrollUp(op.n(), info)
+			return nil
+		},
+	},
+	{
+		Pattern: 0x00FB, Mask: 0xFFFF, Mnemonic: "SCR", Mode: ModeSCHIP, Size: 2,
+		Reads: UseDisplay | UsePlanes, Writes: UseDisplay, Cycles: 24,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.scrollRight(info)
+			return nil
+		},
+	},
+	{
+		Pattern: 0x00FC, Mask: 0xFFFF, Mnemonic: "SCL", Mode: ModeSCHIP, Size: 2,
+		Reads: UseDisplay | UsePlanes, Writes: UseDisplay, Cycles: 24,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.scrollLeft(info)
+			return nil
+		},
+	},
+	{
+		Pattern: 0x00FD, Mask: 0xFFFF, Mnemonic: "EXIT", Mode: ModeSCHIP, Size: 2,
+		Flow: FlowHalt, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.exit(info)
+			return nil
+		},
+	},
+	{
+		Pattern: 0x00FE, Mask: 0xFFFF, Mnemonic: "LOW", Mode: ModeSCHIP, Size: 2,
+		Reads: UsePlanes, Writes: UseDisplay, Cycles: 24,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setResolution(false, info)
+			return nil
+		},
+	},
+	{
+		Pattern: 0x00FF, Mask: 0xFFFF, Mnemonic: "HIGH", Mode: ModeSCHIP, Size: 2,
+		Reads: UsePlanes, Writes: UseDisplay, Cycles: 24,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setResolution(true, info)
+			return nil
+		},
+	},
+	{
+		Pattern: 0x1000, Mask: 0xF000, Mnemonic: "JP", Operands: []OperandKind{OperandAddress}, Mode: ModeCHIP8, Size: 2,
+		Flow: FlowJump, Cycles: 12,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.jumpToLocation(op.nnn())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x2000, Mask: 0xF000, Mnemonic: "CALL", Operands: []OperandKind{OperandAddress}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseStack, Writes: UseStack, Flow: FlowCall, Cycles: 26,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.callSubroutine(op.nnn())
+		},
+	},
+	{
+		Pattern: 0x3000, Mask: 0xF000, Mnemonic: "SE", Operands: []OperandKind{OperandVX, OperandByte}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX, Flow: FlowSkip, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.stepIfXEqualsNN(op.x(), op.nn())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x4000, Mask: 0xF000, Mnemonic: "SNE", Operands: []OperandKind{OperandVX, OperandByte}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX, Flow: FlowSkip, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.stepIfXNotEqualsNN(op.x(), op.nn())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x5000, Mask: 0xF00F, Mnemonic: "SE", Operands: []OperandKind{OperandVX, OperandVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Flow: FlowSkip, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.stepIfXEqualsY(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x5002, Mask: 0xF00F, Mnemonic: "LD", Operands: []OperandKind{OperandIndirect, OperandRange}, Mode: ModeXOCHIP, Size: 2,
+		Reads: UseRange | UseI, Writes: UseMemory, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.setRegisterRangeToMemory(op.x(), op.y())
+		},
+	},
+	{
+		Pattern: 0x5003, Mask: 0xF00F, Mnemonic: "LD", Operands: []OperandKind{OperandRange, OperandIndirect}, Mode: ModeXOCHIP, Size: 2,
+		Reads: UseI | UseMemory, Writes: UseRange, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.setMemoryToRegisterRange(op.x(), op.y())
+		},
+	},
+	{
+		Pattern: 0x6000, Mask: 0xF000, Mnemonic: "LD", Operands: []OperandKind{OperandVX, OperandByte}, Mode: ModeCHIP8, Size: 2,
+		Writes: UseVX, Cycles: 6,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setXToNN(op.x(), op.nn())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x7000, Mask: 0xF000, Mnemonic: "ADD", Operands: []OperandKind{OperandVX, OperandByte}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX, Writes: UseVX, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.addNNToX(op.x(), op.nn())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x8000, Mask: 0xF00F, Mnemonic: "LD", Operands: []OperandKind{OperandVX, OperandVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVY, Writes: UseVX, Cycles: 12,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setXToY(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x8001, Mask: 0xF00F, Mnemonic: "OR", Operands: []OperandKind{OperandVX, OperandVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Writes: UseVX | UseVF, Flag: FlagReset, Cycles: 44,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.orXY(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x8002, Mask: 0xF00F, Mnemonic: "AND", Operands: []OperandKind{OperandVX, OperandVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Writes: UseVX | UseVF, Flag: FlagReset, Cycles: 44,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.andXY(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x8003, Mask: 0xF00F, Mnemonic: "XOR", Operands: []OperandKind{OperandVX, OperandVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Writes: UseVX | UseVF, Flag: FlagReset, Cycles: 44,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.xorXY(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x8004, Mask: 0xF00F, Mnemonic: "ADD", Operands: []OperandKind{OperandVX, OperandVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Writes: UseVX | UseVF, Flag: FlagCarry, Cycles: 44,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.addXY(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x8005, Mask: 0xF00F, Mnemonic: "SUB", Operands: []OperandKind{OperandVX, OperandVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Writes: UseVX | UseVF, Flag: FlagCarry, Cycles: 44,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.subtractYFromX(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x8006, Mask: 0xF00F, Mnemonic: "SHR", Operands: []OperandKind{OperandVX, OperandOptionalVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Writes: UseVX | UseVF, Flag: FlagCarry, Cycles: 44,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.shiftRightX(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x8007, Mask: 0xF00F, Mnemonic: "SUBN", Operands: []OperandKind{OperandVX, OperandVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Writes: UseVX | UseVF, Flag: FlagCarry, Cycles: 44,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.subtractXFromY(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0x800E, Mask: 0xF00F, Mnemonic: "SHL", Operands: []OperandKind{OperandVX, OperandOptionalVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Writes: UseVX | UseVF, Flag: FlagCarry, Cycles: 44,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.shiftLeftX(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		// The interpreter ignores the low nibble.
+		Pattern: 0x9000, Mask: 0xF000, Mnemonic: "SNE", Operands: []OperandKind{OperandVX, OperandVY}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY, Flow: FlowSkip, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.stepIfXNotEqualsY(op.x(), op.y())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xA000, Mask: 0xF000, Mnemonic: "LD", Operands: []OperandKind{OperandI, OperandAddress}, Mode: ModeCHIP8, Size: 2,
+		Writes: UseI, Cycles: 12,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setIToNNN(op.nnn())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xB000, Mask: 0xF000, Mnemonic: "JP", Operands: []OperandKind{OperandV0, OperandAddress}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseV0 | UseVX, Flow: FlowJumpIndirect, Cycles: 22,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.jumpWithOffset(op.x(), op.nnn())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xC000, Mask: 0xF000, Mnemonic: "RND", Operands: []OperandKind{OperandVX, OperandByte}, Mode: ModeCHIP8, Size: 2,
+		Writes: UseVX, Cycles: 36,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setXToRandom(op.x(), op.nn())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xD000, Mask: 0xF000, Mnemonic: "DRW", Operands: []OperandKind{OperandVX, OperandVY, OperandHeight}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseVY | UseI | UseMemory | UseDisplay | UsePlanes, Writes: UseDisplay | UseVF, Flag: FlagCollision, Cycles: 22,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.drawSprite(op.x(), op.y(), op.n(), info)
+		},
+	},
+	{
+		Pattern: 0xE09E, Mask: 0xF0FF, Mnemonic: "SKP", Operands: []OperandKind{OperandVX}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseKeys, Flow: FlowSkip, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.stepIfKeyDown(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xE0A1, Mask: 0xF0FF, Mnemonic: "SKNP", Operands: []OperandKind{OperandVX}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseKeys, Flow: FlowSkip, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.stepIfKeyUp(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF000, Mask: 0xFFFF, Mnemonic: "LD", Operands: []OperandKind{OperandI, OperandLong}, Mode: ModeXOCHIP, Size: 4,
+		Writes: UseI, Cycles: 12,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.setIToLong()
+		},
+	},
+	{
+		Pattern: 0xF001, Mask: 0xF0FF, Mnemonic: "PLANE", Operands: []OperandKind{OperandPlanes}, Mode: ModeXOCHIP, Size: 2,
+		Writes: UsePlanes, Cycles: 6,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.selectPlanes(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF002, Mask: 0xFFFF, Mnemonic: "AUDIO", Mode: ModeXOCHIP, Size: 2,
+		Reads: UseI | UseMemory, Writes: UseAudio, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.setPatternToMemory()
+		},
+	},
+	{
+		Pattern: 0xF007, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandVX, OperandDelay}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseDelay, Writes: UseVX, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setXToDelay(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF00A, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandVX, OperandKey}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseKeys, Writes: UseVX, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.pauseUntilKeyPressed(op.x(), info)
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF015, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandDelay, OperandVX}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX, Writes: UseDelay, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setDelayToX(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF018, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandSound, OperandVX}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX, Writes: UseSound, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setSoundToX(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF01E, Mask: 0xF0FF, Mnemonic: "ADD", Operands: []OperandKind{OperandI, OperandVX}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseI, Writes: UseI, Cycles: 16,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setIToX(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF029, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandFont, OperandVX}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX, Writes: UseI, Cycles: 16,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setIToSymbol(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF030, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandBigFont, OperandVX}, Mode: ModeSCHIP, Size: 2,
+		Reads: UseVX, Writes: UseI, Cycles: 16,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setIToBigSymbol(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF033, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandBCD, OperandVX}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseVX | UseI, Writes: UseMemory, Cycles: 80,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.binaryCodedDecimal(op.x())
+		},
+	},
+	{
+		Pattern: 0xF03A, Mask: 0xF0FF, Mnemonic: "PITCH", Operands: []OperandKind{OperandVX}, Mode: ModeXOCHIP, Size: 2,
+		Reads: UseVX, Writes: UseAudio, Cycles: 10,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setPitchToX(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF055, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandIndirect, OperandVX}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseThroughVX | UseI, Writes: UseMemory | UseI, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.setRegistersToMemory(op.x())
+		},
+	},
+	{
+		Pattern: 0xF065, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandVX, OperandIndirect}, Mode: ModeCHIP8, Size: 2,
+		Reads: UseI | UseMemory, Writes: UseThroughVX | UseI, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			return p.setMemoryToRegisters(op.x())
+		},
+	},
+	{
+		Pattern: 0xF075, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandFlags, OperandVX}, Mode: ModeSCHIP, Size: 2,
+		Reads: UseThroughVX, Writes: UseFlagRegisters, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setRegistersToFlags(op.x())
+			return nil
+		},
+	},
+	{
+		Pattern: 0xF085, Mask: 0xF0FF, Mnemonic: "LD", Operands: []OperandKind{OperandVX, OperandFlags}, Mode: ModeSCHIP, Size: 2,
+		Reads: UseFlagRegisters, Writes: UseThroughVX, Cycles: 14,
+		execute: func(p *Processor, op Opcode, info *uint8) error {
+			p.setFlagsToRegisters(op.x())
+			return nil
+		},
+	},
+}
+
+// decoding holds the instructions by the high nibble of their pattern.
+var decoding = func() (groups [16][]*Instruction) {
+	for i := range Instructions {
+		in := &Instructions[i]
+		groups[in.Pattern.kind()] = append(groups[in.Pattern.kind()], in)
+	}
+	return groups
+}()
+
+// Decode returns the instruction an opcode encodes in any mode, or false
+// when it is not an instruction.
+func Decode(op Opcode) (*Instruction, bool) {
+	for _, in := range decoding[op.kind()] {
+		if op&in.Mask == in.Pattern {
+			return in, true
+		}
+	}
+	return nil, false
+}
+
+// Format writes the instruction an opcode encodes, with its address operand
+// written by address, or a DW directive holding the word when it is not an
+// instruction. LONG is written without its address, which is not part of
+// the opcode.
+func (op Opcode) Format(address func(addr uint16) string) string {
+	in, ok := Decode(op)
+	if !ok {
+		return "DW " + u16toh(uint16(op), 4)
+	}
+
+	str := in.Mnemonic
+	sep := " "
+	for _, k := range in.Operands {
+		var operand string
+		switch k {
+		case OperandVX:
+			operand = "V" + u8toh(op.x(), 1)
+		case OperandVY:
+			operand = "V" + u8toh(op.y(), 1)
+		case OperandOptionalVY:
+			if op.y() == 0 {
+				continue
+			}
+			operand = "V" + u8toh(op.y(), 1)
+		case OperandRange:
+			operand = "V" + u8toh(op.x(), 1) + "-V" + u8toh(op.y(), 1)
+		case OperandNibble:
+			operand = u8toh(op.n(), 1)
+		case OperandHeight:
+			operand = u8toh(op.n(), 2)
+		case OperandPlanes:
+			operand = u8toh(op.x(), 1)
+		case OperandByte:
+			operand = u8toh(op.nn(), 2)
+		case OperandAddress:
+			operand = address(op.nnn())
+		default:
+			operand = k.Keyword()
+		}
+		str += sep + operand
+		sep = ", "
+	}
+	return str
+}
@@ -16,64 +16,123 @@
 
 package chip8
 
-import (
-	"emul8/byteconv"
-	"math/rand/v2"
-)
+import "emul8/byteconv"
 
 func (p *Processor) clearScreen(info *uint8) {
+	// Only the selected bitplanes are cleared.
 	for i := range p.display {
-		p.display[i] = 0
+		p.display[i] &^= p.plane
 	}
 	*info |= Redraw
 }
 
-func (p *Processor) callSubroutine(nnn uint16) {
+func (p *Processor) scrollDown(n uint8, info *uint8) {
+	p.scroll(0, int(n), info)
+}
+
+func (p *Processor) scrollUp(n uint8, info *uint8) {
+	p.scroll(0, -int(n), info)
+}
+
+func (p *Processor) scrollRight(info *uint8) {
+	p.scroll(4, 0, info)
+}
+
+func (p *Processor) scrollLeft(info *uint8) {
+	p.scroll(-4, 0, info)
+}
+
+// scroll moves the selected bitplanes of the display by dx, dy pixels.
+// Pixels scrolled in from outside the display are cleared.
+func (p *Processor) scroll(dx, dy int, info *uint8) {
+	width, height := p.resolution()
+	src := p.display
+
+	for y := range height {
+		for x := range width {
+			var pixel byte
+			if srcX, srcY := x-dx, y-dy; srcX >= 0 && srcX < width && srcY >= 0 && srcY < height {
+				pixel = src[srcX+(srcY*width)] & p.plane
+			}
+			index := x + (y * width)
+			p.display[index] = (p.display[index] &^ p.plane) | pixel
+		}
+	}
+	*info |= Redraw
+}
+
+func (p *Processor) exit(info *uint8) {
+	p.halted = true
+	*info |= Halt
+}
+
+func (p *Processor) setResolution(hires bool, info *uint8) {
+	p.hires = hires
+	p.clearScreen(info)
+}
+
+func (p *Processor) callSubroutine(nnn uint16) error {
 	if int(p.sp) >= len(p.stack) {
-		panic("stack overflow")
+		return ErrStackOverflow
 	}
 	p.stack[p.sp] = p.pc
 	p.sp++
 	p.pc = nnn
+	return nil
 }
 
-func (p *Processor) returnFromSubroutine() {
+func (p *Processor) returnFromSubroutine() error {
 	if p.sp == 0 {
-		panic("stack underflow")
+		return ErrStackUnderflow
 	}
 	p.sp--
 	p.pc = p.stack[p.sp]
+	return nil
 }
 
 func (p *Processor) jumpToLocation(nnn uint16) {
 	p.pc = nnn
 }
 
-func (p *Processor) jumpWithOffset(nnn uint16) {
+func (p *Processor) jumpWithOffset(x uint8, nnn uint16) {
+	if p.quirks.JumpUsesVX {
+		p.pc = nnn + uint16(p.v[x])
+		return
+	}
 	p.pc = nnn + uint16(p.v[0x0])
 }
 
+// skip advances past the next instruction. On XO-CHIP, this is four bytes
+// when the next instruction is the F000 NNNN long load.
+func (p *Processor) skip() {
+	if p.supports(ModeXOCHIP) && p.memory[p.pc] == 0xF0 && p.memory[p.pc+1] == 0x00 {
+		p.pc += 4
+		return
+	}
+	p.pc += 2
+}
+
 func (p *Processor) stepIfXEqualsNN(x, nn uint8) {
 	if p.v[x] == byte(nn) {
-		p.pc += 2
+		p.skip()
 	}
 }
 
 func (p *Processor) stepIfXNotEqualsNN(x, nn uint8) {
 	if p.v[x] != byte(nn) {
-		p.pc += 2
+		p.skip()
 	}
 }
 
 func (p *Processor) stepIfXEqualsY(x, y uint8) {
 	if p.v[x] == p.v[y] {
-		p.pc += 2
+		p.skip()
 	}
 }
 
 func (p *Processor) stepIfXNotEqualsY(x, y uint8) {
 	if p.v[x] != p.v[y] {
-		p.pc += 2
+		p.skip()
 	}
 }
 
@@ -90,57 +149,75 @@ func (p *Processor) setXToY(x, y uint8) {
 }
 
 func (p *Processor) orXY(x, y uint8) {
-	// This operation traditionally resets the carry flag.
-	p.v[CarryFlag] = 0
 	p.v[x] |= p.v[y]
+	if p.quirks.VFReset {
+		// This operation traditionally resets the carry flag.
+		p.v[CarryFlag] = 0
+	}
 }
 
 func (p *Processor) andXY(x, y uint8) {
-	// This operation traditionally resets the carry flag.
-	p.v[CarryFlag] = 0
 	p.v[x] &= p.v[y]
+	if p.quirks.VFReset {
+		// This operation traditionally resets the carry flag.
+		p.v[CarryFlag] = 0
+	}
 }
 
 func (p *Processor) xorXY(x, y uint8) {
-	// This operation traditionally resets the carry flag.
-	p.v[CarryFlag] = 0
 	p.v[x] ^= p.v[y]
+	if p.quirks.VFReset {
+		// This operation traditionally resets the carry flag.
+		p.v[CarryFlag] = 0
+	}
 }
 
+// The arithmetic operations write the flag after the result, so that the
+// flag is kept when VF is the destination.
+
 func (p *Processor) addXY(x, y uint8) {
 	sum := uint16(p.v[x]) + uint16(p.v[y])
-	// This operation traditionally resets the carry flag.
+	p.v[x] = byte(sum & 0xFF)
 	p.v[CarryFlag] = 0
 	if sum > 255 {
 		p.v[CarryFlag] = 1
 	}
-	p.v[x] = byte(sum & 0xFF)
 }
 
 func (p *Processor) subtractYFromX(x, y uint8) {
-	p.v[CarryFlag] = 0
+	var flag uint8
 	if p.v[x] >= p.v[y] {
-		p.v[CarryFlag] = 1
+		flag = 1
 	}
 	p.v[x] -= p.v[y]
+	p.v[CarryFlag] = flag
 }
 
 func (p *Processor) subtractXFromY(x, y uint8) {
-	p.v[CarryFlag] = 0
+	var flag uint8
 	if p.v[y] >= p.v[x] {
-		p.v[CarryFlag] = 1
+		flag = 1
 	}
 	p.v[x] = p.v[y] - p.v[x]
+	p.v[CarryFlag] = flag
 }
 
-func (p *Processor) shiftRightX(x uint8) {
-	p.v[CarryFlag] = p.v[x] & 0x1
+func (p *Processor) shiftRightX(x, y uint8) {
+	if p.quirks.ShiftUsesVY {
+		p.v[x] = p.v[y]
+	}
+	carry := p.v[x] & 0x1
 	p.v[x] >>= 1
+	p.v[CarryFlag] = carry
 }
 
-func (p *Processor) shiftLeftX(x uint8) {
-	p.v[CarryFlag] = (p.v[x] & 0x80) >> 7
+func (p *Processor) shiftLeftX(x, y uint8) {
+	if p.quirks.ShiftUsesVY {
+		p.v[x] = p.v[y]
+	}
+	carry := (p.v[x] & 0x80) >> 7
 	p.v[x] <<= 1
+	p.v[CarryFlag] = carry
 }
 
 func (p *Processor) setIToNNN(nnn uint16) {
@@ -148,54 +225,111 @@ func (p *Processor) setIToNNN(nnn uint16) {
 }
 
 func (p *Processor) setXToRandom(x, nn uint8) {
-	randomByte := byte(rand.Uint32N(256))
+	randomByte := byte(p.rng.Uint64() >> 56)
 	p.v[x] = randomByte & byte(nn)
 }
 
-func (p *Processor) drawSprite(x, y, n uint8, info *uint8) {
-	startX := uint16(p.v[x]) & uint16(Width-1)
-	startY := uint16(p.v[y]) & uint16(Height-1)
+func (p *Processor) drawSprite(x, y, n uint8, info *uint8) error {
+	spriteWidth, spriteHeight := 8, int(n)
+	if n == 0 && p.supports(ModeSCHIP) {
+		// SUPER-CHIP 1.1 draws a 16x16 sprite, stored as two bytes per row,
+		// in high resolution, and an 8x16 sprite in low resolution. XO-CHIP
+		// draws the 16x16 sprite in both.
+		spriteHeight = 16
+		if p.hires || p.supports(ModeXOCHIP) {
+			spriteWidth = 16
+		}
+	}
+	rowSize := spriteWidth / 8
+	spriteSize := spriteHeight * rowSize
+
+	// Each selected bitplane is drawn with its own sprite, stored one after
+	// the other starting at I.
+	var planes int
+	for plane := p.plane; plane != 0; plane >>= 1 {
+		planes += int(plane & 1)
+	}
+
+	if p.quirks.DisplayWait && !p.vblank {
+		// Stall until the next frame by replaying this opcode.
+		p.pc -= 2
+		*info |= Stall
+		return nil
+	}
+
+	if err := p.access(p.i, spriteSize*planes, false); err != nil {
+		return err
+	}
+	p.vblank = false
+
+	width, height := p.resolution()
+
+	startX := int(p.v[x]) & (width - 1)
+	startY := int(p.v[y]) & (height - 1)
 
 	p.v[CarryFlag] = 0 // Reset the collision register.
 
-	for row := range uint16(n) {
-		if startY+row >= uint16(Height) {
-			// Reached the bottom of the display.
-			break
+	addr := int(p.i)
+
+	for bit := uint8(1); bit <= 2; bit <<= 1 {
+		if p.plane&bit == 0 {
+			continue
 		}
 
-		sprite := p.memory[p.i+row]
+		for row := range spriteHeight {
+			posY := startY + row
+			if posY >= height {
+				if !p.quirks.WrapSprites {
+					// Reached the bottom of the display.
+					break
+				}
+				posY -= height
+			}
 
-		for col := range uint16(8) {
-			if startX+col >= uint16(Width) {
-				break
+			// Left-align the row in 16 bits, so that both sprite widths are
+			// drawn the same way.
+			sprite := uint16(p.memory[addr+row*rowSize]) << 8
+			if rowSize == 2 {
+				sprite |= uint16(p.memory[addr+row*rowSize+1])
 			}
 
-			if (sprite & (0x80 >> col)) != 0 {
-				index := (startX + col) + ((startY + row) * uint16(Width))
+			for col := range spriteWidth {
+				posX := startX + col
+				if posX >= width {
+					if !p.quirks.WrapSprites {
+						break
+					}
+					posX -= width
+				}
+
+				if (sprite & (0x8000 >> col)) != 0 {
+					index := posX + (posY * width)
 
-				if p.display[index] == 1 {
-					// Pixel was already on. This indicates a graphical object collision.
-					p.v[CarryFlag] = 1 // Turn on the collision register.
+					if p.display[index]&bit != 0 {
+						// Pixel was already on. This indicates a graphical object collision.
+						p.v[CarryFlag] = 1 // Turn on the collision register.
+					}
+					p.display[index] ^= bit
 				}
-				p.display[index] ^= 1
 			}
 		}
+		addr += spriteSize
 	}
 	*info |= Redraw
+	return nil
 }
 
 func (p *Processor) stepIfKeyDown(x uint8) {
 	key := p.v[x] & 0x0F
 	if p.keyState[key].Load() {
-		p.pc += 2
+		p.skip()
 	}
 }
 
 func (p *Processor) stepIfKeyUp(x uint8) {
 	key := p.v[x] & 0x0F
 	if !p.keyState[key].Load() {
-		p.pc += 2
+		p.skip()
 	}
 }
 
@@ -203,7 +337,7 @@ func (p *Processor) setXToDelay(x uint8) {
 	p.v[x] = p.delay
 }
 
-func (p *Processor) pauseUntilKeyPressed(x uint8) {
+func (p *Processor) pauseUntilKeyPressed(x uint8, info *uint8) {
 	var keyPressed bool
 
 	for i := range uint8(len(p.keyState)) {
@@ -216,6 +350,7 @@ func (p *Processor) pauseUntilKeyPressed(x uint8) {
 
 	if !keyPressed {
 		p.pc -= 2 // Move the program counter back, replaying the last opcode
+		*info |= Stall
 	}
 }
 
@@ -236,7 +371,16 @@ func (p *Processor) setIToSymbol(x uint8) {
 	p.i = FontStartAddress + (digit * 5)
 }
 
-func (p *Processor) binaryCodedDecimal(x uint8) {
+func (p *Processor) setIToBigSymbol(x uint8) {
+	digit := uint16(p.v[x] & 0x0F)
+	p.i = BigFontStartAddress + (digit * 10)
+}
+
+func (p *Processor) binaryCodedDecimal(x uint8) error {
+	if err := p.access(p.i, 3, true); err != nil {
+		return err
+	}
+
 	// Takes the number in register VX (which is one byte, so it can be any number from
 	// 0 to 255) and converts it to three decimal digits, storing these digits in memory
 	// at the address in the index register I. For example, if VX contains 156 (or 9C in
@@ -290,18 +434,128 @@ func (p *Processor) binaryCodedDecimal(x uint8) {
 	p.memory[p.i] = byte((bcd >> 8) & 0xF)   // Hundreds
 	p.memory[p.i+1] = byte((bcd >> 4) & 0xF) // Tens
 	p.memory[p.i+2] = byte(bcd & 0xF)        // Ones
+	return nil
 }
 
-func (p *Processor) setRegistersToMemory(x uint8) {
+func (p *Processor) setRegistersToMemory(x uint8) error {
+	if err := p.access(p.i, int(x)+1, true); err != nil {
+		return err
+	}
+
 	for i := uint8(0); i <= x; i++ {
 		p.memory[p.i+uint16(i)] = p.v[i]
 	}
+	p.incrementIndex(x)
+	return nil
 }
 
-func (p *Processor) setMemoryToRegisters(x uint8) {
+func (p *Processor) setMemoryToRegisters(x uint8) error {
+	if err := p.access(p.i, int(x)+1, false); err != nil {
+		return err
+	}
+
 	for i := uint8(0); i <= x; i++ {
 		p.v[i] = p.memory[p.i+uint16(i)]
 	}
+	p.incrementIndex(x)
+	return nil
+}
+
+func (p *Processor) setIToLong() error {
+	// The address is stored in the word following the opcode.
+	if err := p.access(p.pc, 2, false); err != nil {
+		return err
+	}
+	p.i = (uint16(p.memory[p.pc]) << 8) | uint16(p.memory[p.pc+1])
+	p.pc += 2
+	return nil
+}
+
+func (p *Processor) setRegisterRangeToMemory(x, y uint8) error {
+	step, count := registerRange(x, y)
+
+	if err := p.access(p.i, count, true); err != nil {
+		return err
+	}
+
+	for i := range count {
+		p.memory[int(p.i)+i] = p.v[int(x)+i*step]
+	}
+	return nil
+}
+
+func (p *Processor) setMemoryToRegisterRange(x, y uint8) error {
+	step, count := registerRange(x, y)
+
+	if err := p.access(p.i, count, false); err != nil {
+		return err
+	}
+
+	for i := range count {
+		p.v[int(x)+i*step] = p.memory[int(p.i)+i]
+	}
+	return nil
+}
+
+// registerRange returns the direction and length of the register range VX
+// to VY, which is walked in reverse when X is greater than Y.
+func registerRange(x, y uint8) (step, count int) {
+	if x > y {
+		return -1, int(x-y) + 1
+	}
+	return 1, int(y-x) + 1
+}
+
+func (p *Processor) selectPlanes(n uint8) {
+	p.plane = n & 0x3
+}
+
+func (p *Processor) setPatternToMemory() error {
+	if err := p.access(p.i, PatternSize, false); err != nil {
+		return err
+	}
+	copy(p.pattern[:], p.memory[p.i:])
+	p.patternLoaded = true
+	return nil
+}
+
+func (p *Processor) setPitchToX(x uint8) {
+	p.pitch = p.v[x]
+}
+
+func (p *Processor) setRegistersToFlags(x uint8) {
+	copy(p.flags[:int(x)+1], p.v[:int(x)+1])
+}
+
+func (p *Processor) setFlagsToRegisters(x uint8) {
+	copy(p.v[:int(x)+1], p.flags[:int(x)+1])
+}
+
+func (p *Processor) incrementIndex(x uint8) {
+	switch p.quirks.IndexIncrement {
+	case IndexPlusX:
+		p.i += uint16(x)
+	case IndexPlusXPlus1:
+		p.i += uint16(x) + 1
+	}
+}
+
+// access fails unless the n bytes starting at addr lie within memory. Every
+// instruction that reads or writes memory calls it before doing so, which
+// lets debuggers observe the access and drops cached instructions it
+// overwrites.
+func (p *Processor) access(addr uint16, n int, write bool) error {
+	if int(addr)+n > p.memorySize() {
+		return ErrAddressOutOfRange
+	}
+
+	if p.tracking || p.tracer != nil {
+		p.accesses = append(p.accesses, Access{Address: addr, Size: n, Write: write})
+	}
+	if write {
+		p.invalidate(addr, n)
+	}
+	return nil
 }
 
 type Opcode uint16
@@ -334,104 +588,27 @@ func u16toh(i uint16, n int) string {
 	return byteconv.Btoh(byteconv.U16tob(i), n)
 }
 
+// FormatAddress formats an address in hexadecimal with three digits, or four
+// for addresses beyond the original 4 KiB.
+func FormatAddress(addr uint16) string {
+	if int(addr) < MemorySize {
+		return u16toh(addr, 3)
+	}
+	return u16toh(addr, 4)
+}
+
 func u8toh(i uint8, n int) string {
 	return byteconv.Btoh(byteconv.U16tob(uint16(i)), n)
 }
 
+// Valid reports whether the word is an instruction in any mode.
+func (op Opcode) Valid() bool {
+	_, ok := Decode(op)
+	return ok
+}
+
+// String returns the mnemonic of the instruction, or a DW directive holding
+// the word when it is not an instruction.
 func (op Opcode) String() string {
-	var str string
-
-	switch op.kind() {
-	case 0x0:
-		switch uint16(op) {
-		case 0x00E0:
-			str = "CLS"
-		case 0x00EE:
-			str = "RET"
-		default:
-			panic("unknown 0x0 opcode")
-		}
-	case 0x1:
-		str = "JP " + u16toh(op.nnn(), 3)
-	case 0x2:
-		str = "CALL " + u16toh(op.nnn(), 3)
-	case 0x3:
-		str = "SE V" + u8toh(op.x(), 1) + ", " + u8toh(op.nn(), 2)
-	case 0x4:
-		str = "SNE V" + u8toh(op.x(), 1) + ", " + u8toh(op.nn(), 2)
-	case 0x5:
-		str = "SE V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1)
-	case 0x6:
-		str = "LD V" + u8toh(op.x(), 1) + ", " + u8toh(op.nn(), 2)
-	case 0x7:
-		str = "ADD V" + u8toh(op.x(), 1) + ", " + u8toh(op.nn(), 2)
-	case 0x8:
-		switch op.n() {
-		case 0x0:
-			str = "LD V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1)
-		case 0x1:
-			str = "OR V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1)
-		case 0x2:
-			str = "AND V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1)
-		case 0x3:
-			str = "XOR V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1)
-		case 0x4:
-			str = "ADD V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1)
-		case 0x5:
-			str = "SUB V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1)
-		case 0x6:
-			str = "SHR V" + u8toh(op.x(), 1)
-		case 0x7:
-			str = "SUBN V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1)
-		case 0xE:
-			str = "SHL V" + u8toh(op.x(), 1)
-		default:
-			panic("unknown 0x8 opcode")
-		}
-	case 0x9:
-		str = "SNE V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1)
-	case 0xA:
-		str = "LD I, " + u16toh(op.nnn(), 3)
-	case 0xB:
-		str = "JP V0, " + u16toh(op.nnn(), 3)
-	case 0xC:
-		str = "RND V" + u8toh(op.x(), 1) + ", " + u8toh(op.nn(), 2)
-	case 0xD:
-		str = "DRW V" + u8toh(op.x(), 1) + ", V" + u8toh(op.y(), 1) + ", " + u8toh(op.nn(), 2)
-	case 0xE:
-		switch op.nn() {
-		case 0x9E:
-			str = "SKP V" + u8toh(op.x(), 1)
-		case 0xA1:
-			str = "SKNP V" + u8toh(op.x(), 1)
-		default:
-			panic("unknown 0xE opcode")
-		}
-	case 0xF:
-		switch op.nn() {
-		case 0x07:
-			str = "LD V" + u8toh(op.x(), 1) + ", DT"
-		case 0x0A:
-			str = "LD V" + u8toh(op.x(), 1) + ", K"
-		case 0x15:
-			str = "LD DT, V" + u8toh(op.x(), 1)
-		case 0x18:
-			str = "LD ST, V" + u8toh(op.x(), 1)
-		case 0x1E:
-			str = "ADD I, V" + u8toh(op.x(), 1)
-		case 0x29:
-			str = "LD F, V" + u8toh(op.x(), 1)
-		case 0x33:
-			str = "LD B, V" + u8toh(op.x(), 1)
-		case 0x55:
-			str = "LD [I], V" + u8toh(op.x(), 1)
-		case 0x65:
-			str = "LD V" + u8toh(op.x(), 1) + ", [I]"
-		default:
-			panic("unknown 0xF opcode")
-		}
-	default:
-		panic("unknown opcode")
-	}
-	return str
+	return op.Format(func(addr uint16) string { return u16toh(addr, 3) })
 }
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+// IndexIncrement describes what FX55 and FX65 leave in the index register.
+type IndexIncrement uint8
+
+const (
+	IndexUnchanged  IndexIncrement = iota // I is left as it was.
+	IndexPlusX                            // I is incremented by X.
+	IndexPlusXPlus1                       // I is incremented by X + 1.
+)
+
+// Quirks selects between the behaviors that differ across CHIP-8
+// implementations.
+type Quirks struct {
+	// VFReset clears VF after the 8XY1, 8XY2 and 8XY3 logical operations.
+	VFReset bool
+
+	// ShiftUsesVY makes 8XY6 and 8XYE shift VY into VX, rather than
+	// shifting VX in place.
+	ShiftUsesVY bool
+
+	// IndexIncrement controls how FX55 and FX65 update I.
+	IndexIncrement IndexIncrement
+
+	// JumpUsesVX makes BXNN jump to XNN + VX, rather than NNN + V0.
+	JumpUsesVX bool
+
+	// WrapSprites wraps sprite pixels around the edges of the display,
+	// rather than clipping them.
+	WrapSprites bool
+
+	// DisplayWait limits DXYN to one sprite per 60hz frame, stalling the
+	// processor until the next timer tick.
+	DisplayWait bool
+}
+
+var (
+	// DefaultQuirks is the behavior of this interpreter before quirks were
+	// configurable.
+	DefaultQuirks = Quirks{
+		VFReset: true,
+	}
+
+	QuirksCOSMACVIP = Quirks{
+		VFReset:        true,
+		ShiftUsesVY:    true,
+		IndexIncrement: IndexPlusXPlus1,
+		DisplayWait:    true,
+	}
+
+	QuirksCHIP48 = Quirks{
+		IndexIncrement: IndexPlusX,
+		JumpUsesVX:     true,
+	}
+
+	QuirksSCHIP10 = Quirks{
+		IndexIncrement: IndexPlusX,
+		JumpUsesVX:     true,
+	}
+
+	QuirksSCHIP11 = Quirks{
+		JumpUsesVX: true,
+	}
+
+	QuirksXOCHIP = Quirks{
+		ShiftUsesVY:    true,
+		IndexIncrement: IndexPlusXPlus1,
+		WrapSprites:    true,
+	}
+)
+
+// QuirksPresets maps the names accepted by frontends to the preset profiles.
+var QuirksPresets = map[string]Quirks{
+	"default": DefaultQuirks,
+	"vip":     QuirksCOSMACVIP,
+	"chip48":  QuirksCHIP48,
+	"schip10": QuirksSCHIP10,
+	"schip11": QuirksSCHIP11,
+	"xochip":  QuirksXOCHIP,
+}
@@ -0,0 +1,461 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import "bytes"
+
+// MaxBlockLength bounds the instructions in a block, and so the memory a
+// block can span.
+const MaxBlockLength = 32
+
+// maxBlockSize is the most bytes a block can span.
+const maxBlockSize = MaxBlockLength * 4
+
+// block is a straight run of instructions compiled into a chain of
+// closures, from start up to but not including end. It keeps the bytes it
+// was compiled or translated from in code.
+type block struct {
+	start uint16
+	end   int
+	code  []byte
+	run   link
+}
+
+// Block is code translated ahead of time, as by package recompile, from the
+// bytes in Code. Run runs up to n instructions from Start, leaving the
+// program counter at the next, and returns how many ran, not counting one
+// that waits or faults. It may branch within Code, and runs the
+// instructions it does not translate with ExecuteAt.
+type Block struct {
+	Start uint16
+	Code  []byte
+	Run   func(p *Processor, n int) (int, error)
+}
+
+// link runs an instruction and then the rest of its block, n instructions
+// in all.
+type link func(p *Processor, n int) error
+
+// Divergence reports a block after which the recompiler and the interpreter
+// disagree. Unlike other faults, it is reported after the block has run.
+type Divergence struct {
+	Field string // The state that differs, such as V3 or memory.
+}
+
+func (d *Divergence) Error() string {
+	return "recompiler and interpreter disagree on " + d.Field
+}
+
+// WithRecompiler makes Run, and so RunFrame, compile straight runs of
+// instructions into chains of closures, each run ending at an instruction
+// that jumps, skips, calls, returns, waits for or reads the keypad or
+// writes memory. Writes to memory drop the blocks they overlap. Blocks
+// survive a reset, and run again wherever memory still holds their code, as
+// when a program is loaded again. A processor with a tracer runs one
+// instruction at a time, as without the option.
+func WithRecompiler() Option {
+	return func(p *Processor) {
+		p.recompiling = true
+	}
+}
+
+// WithLockstep checks the recompiler against the interpreter. Each call to
+// Run starts an interpreting copy of the processor from its state, which
+// follows it instruction for instruction; a block or stepped instruction
+// that leaves the two different faults with a *Divergence. The copy draws random numbers from
+// the generator it was seeded with, so a processor with a caller supplied
+// source cannot be checked this way.
+func WithLockstep() Option {
+	return func(p *Processor) {
+		p.recompiling = true
+		p.lockstep = true
+	}
+}
+
+// WithBlocks gives the processor blocks translated ahead of time. Run, and
+// so RunFrame, runs a block whenever the program counter reaches its start
+// while memory holds the bytes it was translated from. Elsewhere, as at the
+// target of a computed jump or in memory the program has overwritten, the
+// processor steps, or compiles its own blocks when recompiling. Blocks with
+// more code than MaxBlockLength long instructions are ignored.
+func WithBlocks(blocks []Block) Option {
+	return func(p *Processor) {
+		p.translated = make(map[uint16]*block, len(blocks))
+		for _, b := range blocks {
+			if len(b.Code) > maxBlockSize {
+				continue
+			}
+			run := b.Run
+			p.translated[b.Start] = &block{start: b.Start, end: int(b.Start) + len(b.Code), code: b.Code, run: func(p *Processor, n int) error {
+				ran, err := run(p, n)
+				p.cycles += uint64(ran)
+				return err
+			}}
+		}
+	}
+}
+
+// EndsBlock reports whether an instruction ends a block. Writing memory
+// ends a block, as the memory may be the block itself. Drawing does not,
+// as a draw that waits for the vertical blank stops its block by itself.
+func (in *Instruction) EndsBlock() bool {
+	return in.Flow != FlowNext || in.Reads&UseKeys != 0 || in.Writes&UseMemory != 0
+}
+
+// blockAt returns the block starting at pc, translated ahead of time or
+// compiled when needed, or nil when the instruction there must be stepped.
+func (p *Processor) blockAt(pc uint16) *block {
+	if int(pc) >= len(p.blocks) || p.tracer != nil || p.halted {
+		return nil
+	}
+
+	b := p.blocks[pc]
+	if b == nil {
+		b = p.reuse(pc)
+		if b == nil && p.recompiling {
+			b = p.compile(pc)
+		}
+		p.blocks[pc] = b
+	}
+	return b
+}
+
+// reuse returns the block translated ahead of time at pc, or else the one
+// compiled there before the last reset. It returns nil when there is none
+// or memory no longer holds the bytes the block was made from.
+func (p *Processor) reuse(pc uint16) *block {
+	b, ok := p.translated[pc]
+	if !ok {
+		b, ok = p.compiled[pc]
+	}
+	if !ok || b.end > p.memorySize() || !bytes.Equal(p.memory[pc:b.end], b.code) {
+		return nil
+	}
+	p.cover(int(pc), b.end)
+	return b
+}
+
+// compile builds the block starting at start, or returns nil when there is
+// no instruction of the processor's mode there.
+func (p *Processor) compile(start uint16) *block {
+	type compiled struct {
+		addr uint16
+		op   Opcode
+		in   *Instruction
+	}
+	var instructions []compiled
+
+	addr := int(start)
+	for len(instructions) < MaxBlockLength {
+		op, err := p.OpcodeAt(uint16(addr))
+		if err != nil {
+			break
+		}
+		in, ok := Decode(op)
+		if !ok || !p.supports(in.Mode) {
+			break
+		}
+
+		instructions = append(instructions, compiled{addr: uint16(addr), op: op, in: in})
+		addr += in.Size
+		if in.EndsBlock() || addr >= p.memorySize() {
+			break
+		}
+	}
+
+	if len(instructions) == 0 {
+		return nil
+	}
+
+	p.cover(int(start), addr)
+
+	var run link
+	for i := len(instructions) - 1; i >= 0; i-- {
+		c := instructions[i]
+		run = chain(c.addr, c.op, c.in, run)
+	}
+	return &block{start: start, end: addr, code: bytes.Clone(p.memory[start:addr]), run: run}
+}
+
+// keepBlocks moves the blocks in memory from start up to end out of the
+// table and into compiled, where reuse finds them after a reset.
+func (p *Processor) keepBlocks(blocks []*block, start, end int) {
+	if p.compiled == nil {
+		p.compiled = make(map[uint16]*block)
+	}
+	clear(p.compiled)
+
+	for addr := start; addr < end; addr++ {
+		if b := blocks[addr]; b != nil {
+			p.compiled[uint16(addr)] = b
+			blocks[addr] = nil
+		}
+	}
+}
+
+// cover widens the memory known to hold blocks to include start up to end.
+func (p *Processor) cover(start, end int) {
+	if p.codeEnd == 0 {
+		p.codeStart = start
+	}
+	p.codeStart, p.codeEnd = min(p.codeStart, start), max(p.codeEnd, end)
+	p.longest = max(p.longest, end-start)
+}
+
+// chain links an instruction to the rest of its block. It runs as Step
+// does, leaving the program counter after the opcode while the instruction
+// executes.
+func chain(addr uint16, op Opcode, in *Instruction, rest link) link {
+	execute := specialize(op, in)
+	next := addr + 2
+
+	return func(p *Processor, n int) error {
+		if n == 0 {
+			return nil
+		}
+
+		p.pc = next
+		if err := execute(p); err != nil {
+			p.pc = addr
+			return &Fault{PC: addr, Opcode: op, Err: err}
+		}
+		if p.info&Stall != 0 {
+			return nil
+		}
+		p.cycles++
+
+		if rest == nil {
+			return nil
+		}
+		return rest(p, n-1)
+	}
+}
+
+// ExecuteAt runs op as the instruction at addr the way a block does, without
+// tracing, ticking the timers or counting a cycle, and faults as Step
+// would. It reports whether the instruction ran, rather than waiting to run
+// again. Blocks translated ahead of time run the instructions they do not
+// translate with it.
+func (p *Processor) ExecuteAt(addr uint16, op Opcode) (bool, error) {
+	p.pc = addr + 2
+	if err := p.Execute(op, &p.info); err != nil {
+		p.pc = addr
+		return false, &Fault{PC: addr, Opcode: op, Err: err}
+	}
+	return p.info&Stall == 0, nil
+}
+
+// V returns the registers, for blocks translated ahead of time to change in
+// place.
+func (p *Processor) V() *[RegisterCount]byte {
+	return &p.v
+}
+
+// SetIndex sets I, for blocks translated ahead of time.
+func (p *Processor) SetIndex(i uint16) {
+	p.i = i
+}
+
+// SetProgramCounter sets the program counter, for blocks translated ahead
+// of time to leave it at the next instruction.
+func (p *Processor) SetProgramCounter(pc uint16) {
+	p.pc = pc
+}
+
+// specialize binds the operands of the common instructions to their
+// handlers, so that they are not decoded again when the block runs. The
+// rest go through the instruction table.
+func specialize(op Opcode, in *Instruction) func(p *Processor) error {
+	x, y, nn, nnn := op.x(), op.y(), op.nn(), op.nnn()
+
+	switch in.Pattern {
+	case 0x1000:
+		return func(p *Processor) error { p.jumpToLocation(nnn); return nil }
+	case 0x3000:
+		return func(p *Processor) error { p.stepIfXEqualsNN(x, nn); return nil }
+	case 0x4000:
+		return func(p *Processor) error { p.stepIfXNotEqualsNN(x, nn); return nil }
+	case 0x5000:
+		return func(p *Processor) error { p.stepIfXEqualsY(x, y); return nil }
+	case 0x9000:
+		return func(p *Processor) error { p.stepIfXNotEqualsY(x, y); return nil }
+	case 0x6000:
+		return func(p *Processor) error { p.setXToNN(x, nn); return nil }
+	case 0x7000:
+		return func(p *Processor) error { p.addNNToX(x, nn); return nil }
+	case 0x8000:
+		return func(p *Processor) error { p.setXToY(x, y); return nil }
+	case 0x8001:
+		return func(p *Processor) error { p.orXY(x, y); return nil }
+	case 0x8002:
+		return func(p *Processor) error { p.andXY(x, y); return nil }
+	case 0x8003:
+		return func(p *Processor) error { p.xorXY(x, y); return nil }
+	case 0x8004:
+		return func(p *Processor) error { p.addXY(x, y); return nil }
+	case 0x8005:
+		return func(p *Processor) error { p.subtractYFromX(x, y); return nil }
+	case 0x8006:
+		return func(p *Processor) error { p.shiftRightX(x, y); return nil }
+	case 0x8007:
+		return func(p *Processor) error { p.subtractXFromY(x, y); return nil }
+	case 0x800E:
+		return func(p *Processor) error { p.shiftLeftX(x, y); return nil }
+	case 0xA000:
+		return func(p *Processor) error { p.setIToNNN(nnn); return nil }
+	case 0xC000:
+		return func(p *Processor) error { p.setXToRandom(x, nn); return nil }
+	case 0xF007:
+		return func(p *Processor) error { p.setXToDelay(x); return nil }
+	case 0xF015:
+		return func(p *Processor) error { p.setDelayToX(x); return nil }
+	case 0xF018:
+		return func(p *Processor) error { p.setSoundToX(x); return nil }
+	case 0xF01E:
+		return func(p *Processor) error { p.setIToX(x); return nil }
+	case 0xF029:
+		return func(p *Processor) error { p.setIToSymbol(x); return nil }
+	}
+	return func(p *Processor) error { return in.execute(p, op, &p.info) }
+}
+
+// runBlock runs up to n instructions of a block, returning the info
+// collected since Run started and how many instructions ran, counting a
+// stalled instruction that ended the block.
+func (p *Processor) runBlock(b *block, n int) (uint8, int, error) {
+	before := p.cycles
+	err := b.run(p, n)
+
+	// A block that loops back to its start runs again without going back
+	// to Run, for as long as it has not been dropped.
+	for err == nil && p.pc == b.start && p.info&(Halt|Stall) == 0 && p.blocks[b.start] == b {
+		left := n - int(p.cycles-before)
+		if left <= 0 {
+			break
+		}
+		err = b.run(p, left)
+	}
+	count := int(p.cycles - before)
+	if p.info&Stall != 0 {
+		count++
+	}
+
+	if p.shadow != nil {
+		err = p.follow(b.start, count, err)
+	}
+
+	return p.info, count, err
+}
+
+// dropBlocks drops the blocks that overlap the n bytes of memory starting
+// at addr. Only the starts within the longest block before addr are looked
+// at.
+func (p *Processor) dropBlocks(addr uint16, n int) {
+	if int(addr) >= p.codeEnd || int(addr)+n <= p.codeStart {
+		return
+	}
+
+	end := min(int(addr)+n, p.codeEnd)
+	for start := max(int(addr)-p.longest+1, p.codeStart); start < end; start++ {
+		if b := p.blocks[start]; b != nil && b.end > int(addr) {
+			p.blocks[start] = nil
+		}
+	}
+}
+
+// sync starts the interpreting copy of the processor from its state.
+func (p *Processor) sync() error {
+	state, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	if p.shadow == nil {
+		p.shadow = NewProcessor(WithMode(p.mode), WithQuirks(p.quirks), WithSeed(p.seed))
+	}
+
+	// Save states leave out the keypad, so the copy is given the keys held.
+	for i := range p.keyState {
+		p.shadow.keyState[i].Store(p.keyState[i].Load())
+	}
+	return p.shadow.UnmarshalBinary(state)
+}
+
+// follow steps the interpreting copy over the instructions a block or Step
+// ran, along with the one that faulted, and compares the two processors.
+func (p *Processor) follow(start uint16, count int, err error) error {
+	steps := count
+	if err != nil {
+		steps++
+	}
+
+	var shadowErr error
+	for range steps {
+		if _, shadowErr = p.shadow.Step(); shadowErr != nil {
+			break
+		}
+	}
+
+	field := p.differs(p.shadow)
+	if (err == nil) != (shadowErr == nil) || err != nil && err.Error() != shadowErr.Error() {
+		field = "fault"
+	}
+
+	if field != "" {
+		op, _ := p.OpcodeAt(start)
+		return &Fault{PC: start, Opcode: op, Err: &Divergence{Field: field}}
+	}
+	return err
+}
+
+// differs names the first state that differs between two processors, or
+// returns an empty string when they agree.
+func (p *Processor) differs(q *Processor) string {
+	for r := range p.v {
+		if p.v[r] != q.v[r] {
+			return "V" + u8toh(uint8(r), 1)
+		}
+	}
+
+	switch {
+	case p.pc != q.pc:
+		return "PC"
+	case p.i != q.i:
+		return "I"
+	case p.sp != q.sp || p.stack != q.stack:
+		return "stack"
+	case p.delay != q.delay:
+		return "DT"
+	case p.sound != q.sound:
+		return "ST"
+	case p.cycles != q.cycles:
+		return "cycles"
+	case p.halted != q.halted:
+		return "halted"
+	case p.vblank != q.vblank:
+		return "vblank"
+	case p.memory != q.memory:
+		return "memory"
+	case p.display != q.display || p.hires != q.hires || p.plane != q.plane:
+		return "display"
+	case p.flags != q.flags:
+		return "flags"
+	case p.pattern != q.pattern || p.patternLoaded != q.patternLoaded || p.pitch != q.pitch:
+		return "audio"
+	}
+	return ""
+}
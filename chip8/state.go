@@ -0,0 +1,282 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math/rand/v2"
+)
+
+// A save state is laid out as:
+//
+//	magic   [7]byte  "EMUL8ST"
+//	version uint8
+//	chunks  ...      tag [4]byte, length uint32, data [length]byte
+//	crc     uint32   CRC-32 (IEEE) of everything before it
+//
+// All integers are big-endian. Readers skip chunks they do not know, and
+// ignore trailing bytes in chunks that have grown, so fields are only ever
+// appended to a chunk or added in a new one. The version is bumped when a
+// change cannot be read by an older release.
+//
+// The keypad is not saved: keys are held by the user rather than the
+// machine, and states written with a KEYS chunk have it skipped.
+const (
+	stateMagic   = "EMUL8ST"
+	stateVersion = 1
+)
+
+const (
+	chunkConfig  = "CONF"
+	chunkCPU     = "CPU "
+	chunkMemory  = "MEM "
+	chunkDisplay = "DISP"
+	chunkFlags   = "FLAG"
+	chunkAudio   = "AUDI"
+	chunkRandom  = "RAND"
+)
+
+// minChunkSize is the size of each chunk as first written. Chunks that are
+// not listed here are optional.
+var minChunkSize = map[string]int{
+	chunkConfig:  1 + 6 + 8,
+	chunkCPU:     RegisterCount + 2 + 2 + 1 + 16*2 + 1 + 1 + 1 + 1,
+	chunkMemory:  MemorySize,
+	chunkDisplay: 1 + 1 + HiResArea,
+}
+
+var (
+	ErrStateFormat   = errors.New("malformed save state")
+	ErrStateVersion  = errors.New("unsupported save state version")
+	ErrStateChecksum = errors.New("save state checksum mismatch")
+)
+
+func (p *Processor) MarshalBinary() ([]byte, error) {
+	b := append([]byte(stateMagic), stateVersion)
+
+	conf := []byte{
+		byte(p.mode),
+		boolToByte(p.quirks.VFReset),
+		boolToByte(p.quirks.ShiftUsesVY),
+		byte(p.quirks.IndexIncrement),
+		boolToByte(p.quirks.JumpUsesVX),
+		boolToByte(p.quirks.WrapSprites),
+		boolToByte(p.quirks.DisplayWait),
+	}
+	conf = binary.BigEndian.AppendUint64(conf, p.seed)
+	b = appendChunk(b, chunkConfig, conf)
+
+	cpu := append([]byte{}, p.v[:]...)
+	cpu = binary.BigEndian.AppendUint16(cpu, p.i)
+	cpu = binary.BigEndian.AppendUint16(cpu, p.pc)
+	cpu = append(cpu, p.sp)
+	for _, addr := range p.stack {
+		cpu = binary.BigEndian.AppendUint16(cpu, addr)
+	}
+	cpu = append(cpu, p.delay, p.sound, boolToByte(p.vblank), boolToByte(p.halted))
+	cpu = binary.BigEndian.AppendUint64(cpu, p.cycles)
+	b = appendChunk(b, chunkCPU, cpu)
+
+	b = appendChunk(b, chunkMemory, p.memory[:p.memorySize()])
+
+	display := append([]byte{boolToByte(p.hires), p.plane}, p.display[:]...)
+	b = appendChunk(b, chunkDisplay, display)
+
+	b = appendChunk(b, chunkFlags, p.flags[:])
+
+	audio := append([]byte{}, p.pattern[:]...)
+	audio = append(audio, boolToByte(p.patternLoaded), p.pitch)
+	b = appendChunk(b, chunkAudio, audio)
+
+	// A caller supplied source cannot be saved. Restoring such a state
+	// reseeds the generator instead.
+	if pcg, ok := p.rng.(*rand.PCG); ok {
+		random, err := pcg.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		b = appendChunk(b, chunkRandom, random)
+	}
+
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b)), nil
+}
+
+// UnmarshalBinary restores a state written by MarshalBinary, including the
+// mode and quirks it was saved with. The processor is left unchanged when
+// the state cannot be read. A processor drawing from a caller supplied
+// source keeps drawing from it, and the keys held keep being held.
+func (p *Processor) UnmarshalBinary(data []byte) error {
+	header := len(stateMagic) + 1
+	if len(data) < header+4 || string(data[:len(stateMagic)]) != stateMagic {
+		return ErrStateFormat
+	}
+
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return ErrStateChecksum
+	}
+
+	if version := body[len(stateMagic)]; version == 0 || version > stateVersion {
+		return ErrStateVersion
+	}
+
+	chunks := make(map[string][]byte)
+	for rest := body[header:]; len(rest) > 0; {
+		if len(rest) < 8 {
+			return ErrStateFormat
+		}
+		tag, size := string(rest[:4]), binary.BigEndian.Uint32(rest[4:8])
+		rest = rest[8:]
+		if uint32(len(rest)) < size {
+			return ErrStateFormat
+		}
+		chunks[tag], rest = rest[:size], rest[size:]
+	}
+
+	// Validate everything before touching the processor.
+	for tag, size := range minChunkSize {
+		if len(chunks[tag]) < size {
+			return ErrStateFormat
+		}
+	}
+
+	mode := Mode(chunks[chunkConfig][0])
+	if mode > ModeXOCHIP {
+		return ErrStateFormat
+	}
+
+	if len(chunks[chunkMemory]) != mode.memorySize() {
+		return ErrStateFormat
+	}
+
+	// Values past these would leave the processor indexing out of range.
+	if IndexIncrement(chunks[chunkConfig][3]) > IndexPlusXPlus1 ||
+		int(chunks[chunkCPU][RegisterCount+4]) > len(p.stack) ||
+		chunks[chunkDisplay][1] > 3 {
+		return ErrStateFormat
+	}
+
+	var pcg *rand.PCG
+	if random, ok := chunks[chunkRandom]; ok {
+		pcg = new(rand.PCG)
+		if err := pcg.UnmarshalBinary(random); err != nil {
+			return ErrStateFormat
+		}
+	}
+
+	conf := stateReader(chunks[chunkConfig][1:])
+	p.mode = mode
+	p.quirks = Quirks{
+		VFReset:        conf.bool(),
+		ShiftUsesVY:    conf.bool(),
+		IndexIncrement: IndexIncrement(conf.byte()),
+		JumpUsesVX:     conf.bool(),
+		WrapSprites:    conf.bool(),
+		DisplayWait:    conf.bool(),
+	}
+	p.seed = conf.uint64()
+
+	var keys [KeyCount]bool
+	for i := range p.keyState {
+		keys[i] = p.keyState[i].Load()
+	}
+
+	p.Reset()
+
+	for i := range p.keyState {
+		p.keyState[i].Store(keys[i])
+	}
+
+	if pcg != nil && p.source == nil {
+		p.rng = pcg
+	}
+
+	cpu := stateReader(chunks[chunkCPU])
+	copy(p.v[:], cpu.bytes(RegisterCount))
+	p.i = cpu.uint16()
+	p.pc = cpu.uint16()
+	p.sp = cpu.byte()
+	for i := range p.stack {
+		p.stack[i] = cpu.uint16()
+	}
+	p.delay = cpu.byte()
+	p.sound = cpu.byte()
+	p.vblank = cpu.bool()
+	p.halted = cpu.bool()
+	if len(cpu) >= 8 {
+		p.cycles = cpu.uint64()
+	}
+
+	copy(p.memory[:], chunks[chunkMemory])
+
+	display := stateReader(chunks[chunkDisplay])
+	p.hires = display.bool()
+	p.plane = display.byte()
+	copy(p.display[:], display.bytes(HiResArea))
+
+	if flags, ok := chunks[chunkFlags]; ok && len(flags) >= FlagCount {
+		copy(p.flags[:], flags)
+	}
+
+	if audio, ok := chunks[chunkAudio]; ok && len(audio) >= PatternSize+2 {
+		r := stateReader(audio)
+		copy(p.pattern[:], r.bytes(PatternSize))
+		p.patternLoaded = r.bool()
+		p.pitch = r.byte()
+	}
+	return nil
+}
+
+func appendChunk(b []byte, tag string, data []byte) []byte {
+	b = append(b, tag...)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+func boolToByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// stateReader consumes a chunk whose size has already been validated.
+type stateReader []byte
+
+func (r *stateReader) bytes(n int) []byte {
+	b := (*r)[:n]
+	*r = (*r)[n:]
+	return b
+}
+
+func (r *stateReader) byte() byte {
+	return r.bytes(1)[0]
+}
+
+func (r *stateReader) bool() bool {
+	return r.byte() != 0
+}
+
+func (r *stateReader) uint16() uint16 {
+	return binary.BigEndian.Uint16(r.bytes(2))
+}
+
+func (r *stateReader) uint64() uint64 {
+	return binary.BigEndian.Uint64(r.bytes(8))
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+// random draws two random numbers in a loop.
+var random = []byte{
+	0xC0, 0xFF, // RND V0, FF
+	0xC1, 0xFF, // RND V1, FF
+	0x12, 0x00, // JP 200
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	p := NewProcessor(WithMode(ModeXOCHIP), WithQuirks(QuirksPresets["xochip"]), WithSeed(42))
+	if err := p.Load(random); err != nil {
+		t.Fatal(err)
+	}
+	for range 30 {
+		if _, err := p.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.TickTimers()
+
+	state, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := NewProcessor(WithSeed(7))
+	if err := q.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := q.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, state) {
+		t.Fatal("state changed through UnmarshalBinary")
+	}
+
+	// The restored generator carries on where the saved one was.
+	for i := range 30 {
+		if _, err := p.Step(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := q.Step(); err != nil {
+			t.Fatal(err)
+		}
+		if p.Register(0) != q.Register(0) || p.Register(1) != q.Register(1) {
+			t.Fatalf("step %d: V0, V1 = %02X, %02X, want %02X, %02X", i, q.Register(0), q.Register(1), p.Register(0), p.Register(1))
+		}
+	}
+}
+
+func TestStateKeepsHeldKeys(t *testing.T) {
+	p := NewProcessor()
+	if err := p.Load(random); err != nil {
+		t.Fatal(err)
+	}
+	p.SetKey(0x5, true)
+
+	state, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := NewProcessor()
+	q.SetKey(0x3, true)
+	if err := q.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+
+	if q.keyState[0x5].Load() {
+		t.Error("key 5 was restored as held")
+	}
+	if !q.keyState[0x3].Load() {
+		t.Error("key 3 was released")
+	}
+}
+
+func TestStateCorrupt(t *testing.T) {
+	p := NewProcessor()
+	state, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := bytes.Clone(state)
+	corrupt[len(corrupt)/2] ^= 0xFF
+	if err := p.UnmarshalBinary(corrupt); err != ErrStateChecksum {
+		t.Errorf("UnmarshalBinary() = %v, want %v", err, ErrStateChecksum)
+	}
+
+	// Out of range values are rejected even with a valid checksum.
+	tests := []struct {
+		name  string
+		chunk string
+		off   int
+		value byte
+	}{
+		{"stack pointer", chunkCPU, RegisterCount + 4, 200},
+		{"stack pointer past the stack", chunkCPU, RegisterCount + 4, 17},
+		{"plane", chunkDisplay, 1, 4},
+		{"index increment", chunkConfig, 3, byte(IndexPlusXPlus1) + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewProcessor()
+			if err := q.UnmarshalBinary(patchState(t, state, tt.chunk, tt.off, tt.value)); err != ErrStateFormat {
+				t.Fatalf("UnmarshalBinary() = %v, want %v", err, ErrStateFormat)
+			}
+			if q.StackDepth() != 0 {
+				t.Errorf("StackDepth() = %d after a rejected state", q.StackDepth())
+			}
+		})
+	}
+}
+
+// patchState returns a copy of a state with the byte at off in a chunk set
+// to value, and its checksum brought up to date.
+func patchState(t *testing.T, state []byte, chunk string, off int, value byte) []byte {
+	t.Helper()
+	state = bytes.Clone(state)
+	body := state[:len(state)-4]
+
+	for at := len(stateMagic) + 1; at < len(body); {
+		size := int(binary.BigEndian.Uint32(body[at+4 : at+8]))
+		if string(body[at:at+4]) == chunk {
+			body[at+8+off] = value
+			binary.BigEndian.PutUint32(state[len(body):], crc32.ChecksumIEEE(body))
+			return state
+		}
+		at += 8 + size
+	}
+	t.Fatalf("no %q chunk in the state", chunk)
+	return nil
+}
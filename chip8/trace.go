@@ -0,0 +1,304 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrTraceFormat = errors.New("malformed trace record")
+
+// Tracer is told about every instruction the processor executes.
+type Tracer interface {
+	Trace(r TraceRecord)
+}
+
+// WithTracer reports every executed instruction to t. The tracer survives
+// a reset.
+func WithTracer(t Tracer) Option {
+	return func(p *Processor) {
+		p.tracer = t
+	}
+}
+
+// SetTracer replaces the tracer, or removes it when t is nil.
+func (p *Processor) SetTracer(t Tracer) {
+	p.tracer = t
+}
+
+// TraceRecord describes one executed instruction. PC and Opcode identify the
+// instruction, while the registers, index, stack pointer and timers are as
+// the instruction left them.
+type TraceRecord struct {
+	Cycle    uint64
+	PC       uint16
+	Opcode   Opcode
+	Mnemonic string
+	V        [RegisterCount]uint8
+	I        uint16
+	SP       uint8
+	DT       uint8
+	ST       uint8
+	Writes   []MemoryWrite
+}
+
+// MemoryWrite is a range of memory written by an instruction.
+type MemoryWrite struct {
+	Address uint16
+	Data    []byte
+}
+
+func (p *Processor) trace(pc uint16, op Opcode) {
+	r := TraceRecord{
+		Cycle:    p.cycles,
+		PC:       pc,
+		Opcode:   op,
+		Mnemonic: op.String(),
+		V:        p.v,
+		I:        p.i,
+		SP:       p.sp,
+		DT:       p.delay,
+		ST:       p.sound,
+	}
+
+	for _, a := range p.accesses {
+		if a.Write {
+			data := append([]byte{}, p.memory[a.Address:int(a.Address)+a.Size]...)
+			r.Writes = append(r.Writes, MemoryWrite{Address: a.Address, Data: data})
+		}
+	}
+	p.tracer.Trace(r)
+}
+
+// A trace is written one record per line, as space separated fields
+// followed by the mnemonic:
+//
+//	cycle=1 pc=0200 op=A300 v=00000000000000000000000000000000 i=0300 sp=00 dt=00 st=00 ; LD I, 300
+//	cycle=2 pc=0202 op=F155 v=0A0B0000000000000000000000000000 i=0300 sp=00 dt=00 st=00 w=0300:0A0B ; LD [I], V1
+//
+// The cycle count is decimal and every other number is hexadecimal. The w
+// field lists the memory written, when there is any, as address:bytes pairs
+// separated by commas. Readers ignore fields they do not know, so that new
+// fields may be added before the mnemonic. The op field may be left out by
+// emulators that only print mnemonics, and reads as 0000, which is never
+// executed.
+func (r TraceRecord) String() string {
+	var b strings.Builder
+
+	b.WriteString("cycle=" + strconv.FormatUint(r.Cycle, 10))
+	b.WriteString(" pc=" + u16toh(r.PC, 4))
+	b.WriteString(" op=" + u16toh(uint16(r.Opcode), 4))
+	b.WriteString(" v=" + strings.ToUpper(hex.EncodeToString(r.V[:])))
+	b.WriteString(" i=" + u16toh(r.I, 4))
+	b.WriteString(" sp=" + u8toh(r.SP, 2))
+	b.WriteString(" dt=" + u8toh(r.DT, 2))
+	b.WriteString(" st=" + u8toh(r.ST, 2))
+
+	for i, w := range r.Writes {
+		if i == 0 {
+			b.WriteString(" w=")
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(u16toh(w.Address, 4) + ":" + strings.ToUpper(hex.EncodeToString(w.Data)))
+	}
+
+	b.WriteString(" ; " + r.Mnemonic)
+	return b.String()
+}
+
+// ParseTraceRecord reads a line in the form written by TraceRecord.String.
+func ParseTraceRecord(line string) (TraceRecord, error) {
+	var r TraceRecord
+
+	fields, mnemonic, ok := strings.Cut(line, " ; ")
+	if !ok {
+		return r, ErrTraceFormat
+	}
+	r.Mnemonic = mnemonic
+
+	seen := make(map[string]bool)
+
+	for _, field := range strings.Fields(fields) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return r, ErrTraceFormat
+		}
+
+		var err error
+		switch key {
+		case "cycle":
+			r.Cycle, err = strconv.ParseUint(value, 10, 64)
+		case "pc":
+			r.PC, err = parseTraceUint16(value)
+		case "op":
+			var op uint16
+			op, err = parseTraceUint16(value)
+			r.Opcode = Opcode(op)
+		case "v":
+			var v []byte
+			if v, err = hex.DecodeString(value); err == nil && len(v) != RegisterCount {
+				err = ErrTraceFormat
+			}
+			copy(r.V[:], v)
+		case "i":
+			r.I, err = parseTraceUint16(value)
+		case "sp":
+			r.SP, err = parseTraceUint8(value)
+		case "dt":
+			r.DT, err = parseTraceUint8(value)
+		case "st":
+			r.ST, err = parseTraceUint8(value)
+		case "w":
+			r.Writes, err = parseTraceWrites(value)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return r, ErrTraceFormat
+		}
+		seen[key] = true
+	}
+
+	for _, key := range []string{"cycle", "pc", "v", "i", "sp", "dt", "st"} {
+		if !seen[key] {
+			return r, ErrTraceFormat
+		}
+	}
+	return r, nil
+}
+
+func parseTraceUint16(s string) (uint16, error) {
+	n, err := strconv.ParseUint(s, 16, 16)
+	return uint16(n), err
+}
+
+func parseTraceUint8(s string) (uint8, error) {
+	n, err := strconv.ParseUint(s, 16, 8)
+	return uint8(n), err
+}
+
+func parseTraceWrites(s string) ([]MemoryWrite, error) {
+	var writes []MemoryWrite
+
+	for _, w := range strings.Split(s, ",") {
+		addr, data, ok := strings.Cut(w, ":")
+		if !ok {
+			return nil, ErrTraceFormat
+		}
+
+		a, err := parseTraceUint16(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := hex.DecodeString(data)
+		if err != nil || len(d) == 0 {
+			return nil, ErrTraceFormat
+		}
+		writes = append(writes, MemoryWrite{Address: a, Data: d})
+	}
+	return writes, nil
+}
+
+// TraceWriter is a Tracer that writes records to an io.Writer. Output is
+// buffered until Flush is called. Writing stops at the first error, which is
+// returned by Flush.
+type TraceWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func NewTraceWriter(w io.Writer) *TraceWriter {
+	return &TraceWriter{w: bufio.NewWriter(w)}
+}
+
+func (t *TraceWriter) Trace(r TraceRecord) {
+	if t.err != nil {
+		return
+	}
+
+	if _, err := t.w.WriteString(r.String() + "\n"); err != nil {
+		t.err = err
+	}
+}
+
+func (t *TraceWriter) Flush() error {
+	if t.err != nil {
+		return t.err
+	}
+	t.err = t.w.Flush()
+	return t.err
+}
+
+// TraceError reports a line of a trace that could not be read.
+type TraceError struct {
+	Line int
+	Err  error
+}
+
+func (e *TraceError) Error() string {
+	return "trace line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+func (e *TraceError) Unwrap() error {
+	return e.Err
+}
+
+// TraceReader reads the records of a trace. Blank lines are skipped.
+type TraceReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func NewTraceReader(r io.Reader) *TraceReader {
+	return &TraceReader{s: bufio.NewScanner(r)}
+}
+
+// Next returns the next record, or io.EOF once the trace is exhausted.
+func (t *TraceReader) Next() (TraceRecord, error) {
+	for t.s.Scan() {
+		t.line++
+
+		line := strings.TrimSpace(t.s.Text())
+		if line == "" {
+			continue
+		}
+
+		r, err := ParseTraceRecord(line)
+		if err != nil {
+			return r, &TraceError{Line: t.line, Err: err}
+		}
+		return r, nil
+	}
+
+	if err := t.s.Err(); err != nil {
+		return TraceRecord{}, err
+	}
+	return TraceRecord{}, io.EOF
+}
+
+// Line returns the line number of the last record read.
+func (t *TraceReader) Line() int {
+	return t.line
+}
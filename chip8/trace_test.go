@@ -0,0 +1,102 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chip8
+
+import (
+	"reflect"
+	"testing"
+)
+
+// records is a Tracer that keeps every record.
+type records []TraceRecord
+
+func (r *records) Trace(record TraceRecord) {
+	*r = append(*r, record)
+}
+
+func TestTraceSkipsStalls(t *testing.T) {
+	var traced records
+	p := NewProcessor(WithQuirks(Quirks{DisplayWait: true}), WithTracer(&traced))
+	if err := p.Load([]byte{
+		0xD0, 0x05, // DRW V0, V0, 5
+		0xF0, 0x0A, // LD V0, K
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The draw waits for the vertical blank, and the key wait for a key.
+	for range 10 {
+		info, err := p.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info&Stall == 0 {
+			t.Fatal("DRW did not stall before the vertical blank")
+		}
+	}
+	p.TickTimers()
+	for range 10 {
+		if _, err := p.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.SetKey(0x7, true)
+	if _, err := p.Step(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Cycles() != 2 {
+		t.Errorf("Cycles() = %d, want 2", p.Cycles())
+	}
+	if len(traced) != 2 {
+		t.Fatalf("traced %d records, want 2", len(traced))
+	}
+	for i, want := range []uint16{0x200, 0x202} {
+		if r := traced[i]; r.PC != want || r.Cycle != uint64(i+1) {
+			t.Errorf("record %d: cycle=%d pc=%03X, want cycle=%d pc=%03X", i, r.Cycle, r.PC, i+1, want)
+		}
+	}
+	if traced[1].V[0] != 0x7 {
+		t.Errorf("V0 = %X, want 7", traced[1].V[0])
+	}
+}
+
+func TestTraceRecordRoundTrip(t *testing.T) {
+	r := TraceRecord{
+		Cycle:    2,
+		PC:       0x202,
+		Opcode:   0xF155,
+		Mnemonic: "LD [I], V1",
+		V:        [RegisterCount]uint8{0x0A, 0x0B},
+		I:        0x300,
+		Writes:   []MemoryWrite{{Address: 0x300, Data: []byte{0x0A, 0x0B}}},
+	}
+
+	line := r.String()
+	want := "cycle=2 pc=0202 op=F155 v=0A0B0000000000000000000000000000 i=0300 sp=00 dt=00 st=00 w=0300:0A0B ; LD [I], V1"
+	if line != want {
+		t.Errorf("String() = %q, want %q", line, want)
+	}
+
+	parsed, err := ParseTraceRecord(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, r) {
+		t.Errorf("ParseTraceRecord() = %+v, want %+v", parsed, r)
+	}
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command emul8-tracediff finds the first instruction at which two traces
+// diverge. It exits with status 0 when the traces agree, 1 when they
+// diverge and 2 when a trace cannot be read.
+package main
+
+import (
+	"emul8/chip8"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// entry is a record along with the line it was read from.
+type entry struct {
+	line   int
+	record chip8.TraceRecord
+}
+
+type trace struct {
+	name string
+	r    *chip8.TraceReader
+}
+
+// next returns the next entry, or false at the end of the trace.
+func (t *trace) next() (entry, bool) {
+	r, err := t.r.Next()
+	if errors.Is(err, io.EOF) {
+		return entry{}, false
+	}
+	if err != nil {
+		log.Print(t.name + ": " + err.Error())
+		os.Exit(2)
+	}
+	return entry{line: t.r.Line(), record: r}, true
+}
+
+func open(name string) *trace {
+	f, err := os.Open(name)
+	if err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+	return &trace{name: name, r: chip8.NewTraceReader(f)}
+}
+
+// hexNumber matches a hexadecimal number standing on its own, with any of
+// the prefixes used by other emulators.
+var hexNumber = regexp.MustCompile(`(^|[ ,\[(])(?:0X|#|\$)?0*([0-9A-F]+)\b`)
+
+// normalize rewrites a mnemonic so that equivalent spellings compare equal:
+// case, spacing, number prefixes and leading zeros are not significant.
+func normalize(mnemonic string) string {
+	s := strings.Join(strings.Fields(strings.ToUpper(mnemonic)), " ")
+	s = strings.ReplaceAll(s, " ,", ",")
+	s = strings.ReplaceAll(s, ", ", ",")
+	return hexNumber.ReplaceAllString(s, "$1$2")
+}
+
+// byteWrite is a single byte of a memory write. Writes are compared a byte
+// at a time, so a block write matches the same bytes written one by one.
+type byteWrite struct {
+	addr  uint16
+	value byte
+}
+
+func flatten(writes []chip8.MemoryWrite) []byteWrite {
+	var bytes []byteWrite
+	for _, w := range writes {
+		for i, b := range w.Data {
+			bytes = append(bytes, byteWrite{addr: w.Address + uint16(i), value: b})
+		}
+	}
+	return bytes
+}
+
+// compare returns the names of the fields that differ between two records.
+func compare(a, b chip8.TraceRecord, timers bool) []string {
+	var fields []string
+
+	if a.PC != b.PC {
+		fields = append(fields, "pc")
+	}
+
+	// The opcode decides, as emulators spell the same instruction in
+	// different ways. A trace that gives no opcode leaves the mnemonic.
+	if a.Opcode == 0 || b.Opcode == 0 {
+		if normalize(a.Mnemonic) != normalize(b.Mnemonic) {
+			fields = append(fields, "mnemonic")
+		}
+	} else if a.Opcode != b.Opcode {
+		fields = append(fields, "op")
+	}
+
+	for x := range a.V {
+		if a.V[x] != b.V[x] {
+			fields = append(fields, "v"+strconv.FormatUint(uint64(x), 16))
+		}
+	}
+
+	if a.I != b.I {
+		fields = append(fields, "i")
+	}
+
+	wa, wb := flatten(a.Writes), flatten(b.Writes)
+	if len(wa) != len(wb) {
+		fields = append(fields, "w")
+	} else {
+		for n := range wa {
+			if wa[n] != wb[n] {
+				fields = append(fields, "w")
+				break
+			}
+		}
+	}
+
+	if timers {
+		if a.SP != b.SP {
+			fields = append(fields, "sp")
+		}
+		if a.DT != b.DT {
+			fields = append(fields, "dt")
+		}
+		if a.ST != b.ST {
+			fields = append(fields, "st")
+		}
+	}
+	return fields
+}
+
+func printEntry(prefix string, e entry) {
+	os.Stdout.WriteString(prefix + " " + strconv.Itoa(e.line) + ": " + e.record.String() + "\n")
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("emul8-tracediff: ")
+
+	context := flag.Int("context", 5, "number of records to show around the divergence")
+	timers := flag.Bool("timers", false, "also compare the stack pointer and timers")
+	flag.Usage = func() {
+		os.Stderr.WriteString("usage: emul8-tracediff [flags] a.trace b.trace\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	a, b := open(flag.Arg(0)), open(flag.Arg(1))
+
+	// before holds the last records that agreed, for context.
+	var before []entry
+
+	for n := 1; ; n++ {
+		ea, okA := a.next()
+		eb, okB := b.next()
+
+		if !okA && !okB {
+			os.Stdout.WriteString("traces agree for " + strconv.Itoa(n-1) + " records\n")
+			return
+		}
+
+		var fields []string
+		switch {
+		case !okA:
+			os.Stdout.WriteString(a.name + " ends after " + strconv.Itoa(n-1) + " records\n")
+		case !okB:
+			os.Stdout.WriteString(b.name + " ends after " + strconv.Itoa(n-1) + " records\n")
+		default:
+			fields = compare(ea.record, eb.record, *timers)
+			if len(fields) == 0 {
+				before = append(before, ea)
+				if len(before) > *context {
+					before = before[1:]
+				}
+				continue
+			}
+			os.Stdout.WriteString("first divergence at record " + strconv.Itoa(n) +
+				" (cycle " + strconv.FormatUint(ea.record.Cycle, 10) + "): " + strings.Join(fields, ", ") + "\n")
+		}
+
+		os.Stdout.WriteString("--- " + a.name + "\n+++ " + b.name + "\n")
+		for _, e := range before {
+			printEntry(" ", e)
+		}
+
+		// Show the divergence and what followed it in each trace.
+		for i, ok := 0, okA; ok && i <= *context; i++ {
+			printEntry("-", ea)
+			ea, ok = a.next()
+		}
+		for i, ok := 0, okB; ok && i <= *context; i++ {
+			printEntry("+", eb)
+			eb, ok = b.next()
+		}
+		os.Exit(1)
+	}
+}
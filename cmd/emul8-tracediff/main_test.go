@@ -0,0 +1,106 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8/chip8"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func records(t *testing.T, trace string) []chip8.TraceRecord {
+	t.Helper()
+
+	var rs []chip8.TraceRecord
+	r := chip8.NewTraceReader(strings.NewReader(trace))
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return rs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, record)
+	}
+}
+
+// diff returns the fields of the first pair of records that differ.
+func diff(t *testing.T, a, b string) []string {
+	t.Helper()
+
+	ra, rb := records(t, a), records(t, b)
+	if len(ra) != len(rb) {
+		t.Fatalf("traces have %d and %d records", len(ra), len(rb))
+	}
+	for i := range ra {
+		if fields := compare(ra[i], rb[i], true); fields != nil {
+			return fields
+		}
+	}
+	return nil
+}
+
+const ours = `
+cycle=1 pc=0200 op=A300 v=00000000000000000000000000000000 i=0300 sp=00 dt=00 st=00 ; LD I, 300
+cycle=2 pc=0202 op=6181 v=00810000000000000000000000000000 i=0300 sp=00 dt=00 st=00 ; LD V1, 81
+cycle=3 pc=0204 op=8106 v=00400000000000000000000000000001 i=0300 sp=00 dt=00 st=00 ; SHR V1
+cycle=4 pc=0206 op=F155 v=00400000000000000000000000000001 i=0300 sp=00 dt=00 st=00 w=0300:0040 ; LD [I], V1
+`
+
+func TestFormattingAgrees(t *testing.T) {
+	theirs := `
+cycle=1 pc=0200 op=A300 v=00000000000000000000000000000000 i=0300 sp=00 dt=00 st=00 ; mov i, #0300
+cycle=2 pc=0202 op=6181 v=00810000000000000000000000000000 i=0300 sp=00 dt=00 st=00 ; ld v1,0x81
+cycle=3 pc=0204 op=8106 v=00400000000000000000000000000001 i=0300 sp=00 dt=00 st=00 ; SHR V1, V0
+cycle=4 pc=0206 op=F155 v=00400000000000000000000000000001 i=0300 sp=00 dt=00 st=00 w=0300:00,0301:40 ; save v1
+`
+	if fields := diff(t, ours, theirs); fields != nil {
+		t.Errorf("traces diverge on %v", fields)
+	}
+}
+
+func TestMnemonicsWithoutOpcodes(t *testing.T) {
+	theirs := `
+cycle=1 pc=0200 v=00000000000000000000000000000000 i=0300 sp=00 dt=00 st=00 ; ld  i , $0300
+cycle=2 pc=0202 v=00810000000000000000000000000000 i=0300 sp=00 dt=00 st=00 ; LD V1, 0x81
+cycle=3 pc=0204 v=00400000000000000000000000000001 i=0300 sp=00 dt=00 st=00 ; shr v1
+cycle=4 pc=0206 v=00400000000000000000000000000001 i=0300 sp=00 dt=00 st=00 w=0300:0040 ; LD [I], V1
+`
+	if fields := diff(t, ours, theirs); fields != nil {
+		t.Errorf("traces diverge on %v", fields)
+	}
+
+	differs := strings.Replace(theirs, "shr v1", "shl v1", 1)
+	if fields := diff(t, ours, differs); !slices.Equal(fields, []string{"mnemonic"}) {
+		t.Errorf("diverge on %v, want [mnemonic]", fields)
+	}
+}
+
+func TestDivergence(t *testing.T) {
+	theirs := strings.Replace(ours, "op=8106 v=00400000000000000000000000000001", "op=8106 v=00400000000000000000000000000000", 1)
+	if fields := diff(t, ours, theirs); !slices.Equal(fields, []string{"vf"}) {
+		t.Errorf("diverge on %v, want [vf]", fields)
+	}
+
+	theirs = strings.Replace(ours, "op=8106", "op=810E", 1)
+	if fields := diff(t, ours, theirs); !slices.Equal(fields, []string{"op"}) {
+		t.Errorf("diverge on %v, want [op]", fields)
+	}
+}
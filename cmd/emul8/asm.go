@@ -0,0 +1,71 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8/asm"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+)
+
+func asmCommand(args []string) {
+	fs := flag.NewFlagSet("asm", flag.ExitOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: emul8 asm [flags] [source]\n\nWith no source, or with -, the source is read from standard input.\n\n"))
+		fs.PrintDefaults()
+	}
+
+	output := fs.String("o", "-", "file to write the ROM to, or - for standard output")
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	name := fs.Arg(0)
+	var src []byte
+	if name == "" || name == "-" {
+		name = "<stdin>"
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fatal(err)
+		}
+		src = b
+	} else {
+		src = readFile(name)
+	}
+
+	rom, err := asm.Assemble(src)
+	var list asm.ErrorList
+	if errors.As(err, &list) {
+		for _, e := range list {
+			log.Print(name + ":" + e.Error())
+		}
+		os.Exit(exitFailure)
+	}
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := writeOutput(*output, rom); err != nil {
+		fatal(err)
+	}
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8/asm"
+	"emul8/chip8"
+	"emul8/conformance"
+	"flag"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// benchEngines are the engines bench measures, compared against the first.
+var benchEngines = []string{"interpreter", "cached", "recompiler"}
+
+// benchPrograms are loops that keep the processor busy, unlike the corpus
+// programs, which mostly wait once they have drawn their results.
+var benchPrograms = []struct{ name, src string }{
+	{"arith", `
+start:  LD V0, 00
+        LD V1, 01
+loop:   ADD V0, V1
+        LD V2, V0
+        SHR V2
+        XOR V3, V2
+        ADD V4, 03
+        SUB V5, V4
+        OR V6, V5
+        AND V6, V3
+        LD V7, V6
+        SHL V7
+        ADD V1, 01
+        SE V1, 00
+        JP loop
+        JP start
+`},
+	{"copy", `
+loop:   LD I, buffer
+        LD [I], V7
+        LD I, buffer
+        LD V7, [I]
+        ADD V0, 01
+        LD I, digits
+        LD B, V0
+        LD V2, [I]
+        JP loop
+digits: DB 00, 00, 00
+buffer: DB 00, 00, 00, 00, 00, 00, 00, 00
+`},
+	{"draw", `
+loop:   LD I, sprite
+        DRW V0, V1, 5
+        ADD V0, 01
+        ADD V1, 02
+        JP loop
+sprite: DB F0, 90, F0, 90, F0
+`},
+}
+
+// workload is a program to measure, with the options it runs under.
+type workload struct {
+	name    string
+	rom     []byte
+	options []chip8.Option
+}
+
+func benchCommand(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: emul8 bench [flags] [rom...]\n\nWithout ROMs, built in loops and the programs of the conformance corpus are measured.\n\n"))
+		fs.PrintDefaults()
+	}
+
+	var m machineFlags
+	m.register(fs)
+
+	cycles := fs.Int("cycles", 10_000_000, "instructions to run for each measurement")
+	fs.Parse(args)
+
+	if *cycles <= 0 {
+		fatal("-cycles must be positive")
+	}
+
+	var workloads []workload
+	if fs.NArg() == 0 {
+		workloads = append(programWorkloads(), corpusWorkloads()...)
+	} else {
+		opts, err := m.options()
+		if err != nil {
+			fatal(err)
+		}
+		for _, name := range fs.Args() {
+			workloads = append(workloads, workload{name: filepath.Base(name), rom: loadROM(name), options: opts})
+		}
+	}
+
+	header := []string{"program"}
+	header = append(header, benchEngines...)
+	for _, e := range benchEngines[1:] {
+		header = append(header, e+" speedup")
+	}
+	rows := [][]string{header}
+
+	totals := make([]time.Duration, len(benchEngines))
+	for _, w := range workloads {
+		row := []string{w.name}
+		elapsed := make([]time.Duration, len(benchEngines))
+
+		for i, e := range benchEngines {
+			d, err := measure(w, chip8.Engines[e], *cycles)
+			if err != nil {
+				fatal(w.name + ": " + err.Error())
+			}
+			elapsed[i] = d
+			totals[i] += d
+			row = append(row, rate(*cycles, d))
+		}
+		for i := range benchEngines[1:] {
+			row = append(row, speedup(elapsed[0], elapsed[i+1]))
+		}
+		rows = append(rows, row)
+	}
+
+	total := []string{"total"}
+	for _, d := range totals {
+		total = append(total, rate(*cycles*len(workloads), d))
+	}
+	for i := range benchEngines[1:] {
+		total = append(total, speedup(totals[0], totals[i+1]))
+	}
+	rows = append(rows, total)
+
+	os.Stdout.WriteString(table(rows))
+}
+
+func programWorkloads() []workload {
+	var workloads []workload
+	for _, prog := range benchPrograms {
+		rom, err := asm.Assemble([]byte(prog.src))
+		if err != nil {
+			fatal(prog.name + ": " + err.Error())
+		}
+		workloads = append(workloads, workload{name: prog.name, rom: rom, options: []chip8.Option{chip8.WithSeed(conformance.Seed)}})
+	}
+	return workloads
+}
+
+// corpusWorkloads returns each program of the conformance corpus once, with
+// the mode and quirks of its first case.
+func corpusWorkloads() []workload {
+	cases, err := conformance.Cases(conformance.Corpus)
+	if err != nil {
+		fatal(err)
+	}
+
+	var workloads []workload
+	seen := make(map[string]bool)
+	for _, c := range cases {
+		if seen[c.ROM] {
+			continue
+		}
+		seen[c.ROM] = true
+
+		src, err := fs.ReadFile(conformance.Corpus, path.Join("roms", c.ROM+".hex"))
+		if err != nil {
+			fatal(err)
+		}
+		rom, err := conformance.ParseHex(src)
+		if err != nil {
+			fatal(c.ROM + ".hex: " + err.Error())
+		}
+
+		workloads = append(workloads, workload{
+			name:    c.ROM,
+			rom:     rom,
+			options: []chip8.Option{chip8.WithMode(c.Mode), chip8.WithQuirks(chip8.QuirksPresets[c.Quirks]), chip8.WithSeed(conformance.Seed)},
+		})
+	}
+	return workloads
+}
+
+// measure times a run of n instructions of a workload, a frame at a time.
+// A program that exits is started again, outside the time measured.
+func measure(w workload, engine []chip8.Option, n int) (time.Duration, error) {
+	p := chip8.NewProcessor(slices.Concat(w.options, engine)...)
+	if err := p.Load(w.rom); err != nil {
+		return 0, err
+	}
+
+	var restarts time.Duration
+	start := time.Now()
+
+	for executed := 0; executed < n; {
+		frame := min(chip8.DefaultCyclesPerFrame, n-executed)
+		before := p.Cycles()
+		if _, err := p.RunFrame(frame); err != nil {
+			return 0, err
+		}
+
+		// A frame uses up its instructions, counting the replays of one
+		// that waits, unless the program exits.
+		if !p.Halted() {
+			executed += frame
+			continue
+		}
+		executed += int(p.Cycles() - before)
+
+		restart := time.Now()
+		p.Reset()
+		if err := p.Load(w.rom); err != nil {
+			return 0, err
+		}
+		restarts += time.Since(restart)
+	}
+	return time.Since(start) - restarts, nil
+}
+
+// rate formats n instructions in d as millions of instructions per second.
+func rate(n int, d time.Duration) string {
+	return strconv.FormatFloat(float64(n)/d.Seconds()/1e6, 'f', 1, 64) + " MIPS"
+}
+
+func speedup(base, d time.Duration) string {
+	return strconv.FormatFloat(base.Seconds()/d.Seconds(), 'f', 2, 64) + "x"
+}
+
+// table aligns rows into columns, the first to the left and the rest to
+// the right.
+func table(rows [][]string) string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			pad := strings.Repeat(" ", widths[i]-len(cell))
+			if i == 0 {
+				b.WriteString(cell + pad)
+			} else {
+				b.WriteString("  " + pad + cell)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8/conformance"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+func conformanceCommand(args []string) {
+	fs := flag.NewFlagSet("conformance", flag.ExitOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: emul8 conformance [flags]\n"))
+		fs.PrintDefaults()
+	}
+
+	dir := fs.String("dir", "", "corpus directory (default the built in corpus)")
+	update := fs.Bool("update", false, "rewrite the golden displays in -dir from the current behavior")
+	pattern := fs.String("run", "", "only run cases whose names match this regular expression")
+	verbose := fs.Bool("v", false, "list every case, not just failures")
+	engine := fs.String("engine", "cached", "execution engine: interpreter, cached, recompiler or lockstep")
+	fs.Parse(args)
+
+	opts := engineOptions(*engine)
+
+	corpus := conformance.Corpus
+	if *dir != "" {
+		corpus = os.DirFS(*dir)
+	} else if *update {
+		fatal("-update needs -dir, as the built in corpus cannot be written")
+	}
+
+	match, err := regexp.Compile(*pattern)
+	if err != nil {
+		fatal(err)
+	}
+
+	cases, err := conformance.Cases(corpus)
+	if err != nil {
+		fatal(err)
+	}
+
+	var ran, failed int
+	for _, c := range cases {
+		if !match.MatchString(c.Name) {
+			continue
+		}
+		ran++
+
+		if *update {
+			got, err := c.Run(corpus, opts...)
+			if err != nil {
+				fatal(c.Name + ": " + err.Error())
+			}
+			if err := os.WriteFile(filepath.Join(*dir, filepath.FromSlash(c.GoldenPath())), []byte(got), 0o644); err != nil {
+				fatal(err)
+			}
+			if *verbose {
+				os.Stdout.WriteString("updated " + c.Name + "\n")
+			}
+			continue
+		}
+
+		r := conformance.Check(corpus, c, opts...)
+		if !r.Passed() {
+			failed++
+			os.Stdout.WriteString("FAIL " + c.Name + "\n" + r.Diff() + "\n")
+		} else if *verbose {
+			os.Stdout.WriteString("ok   " + c.Name + "\n")
+		}
+	}
+
+	if *update {
+		os.Stdout.WriteString("updated " + strconv.Itoa(ran) + " goldens\n")
+		return
+	}
+
+	os.Stdout.WriteString(strconv.Itoa(ran-failed) + " of " + strconv.Itoa(ran) + " cases passed\n")
+	if failed > 0 {
+		os.Exit(exitFailure)
+	}
+}
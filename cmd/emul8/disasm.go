@@ -0,0 +1,44 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8/disasm"
+	"flag"
+	"os"
+)
+
+func disasmCommand(args []string) {
+	fs := flag.NewFlagSet("disasm", flag.ExitOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: emul8 disasm [flags] rom\n"))
+		fs.PrintDefaults()
+	}
+
+	output := fs.String("o", "-", "file to write the source to, or - for standard output")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	src := disasm.Disassemble(loadROM(fs.Arg(0)))
+	if err := writeOutput(*output, []byte(src)); err != nil {
+		fatal(err)
+	}
+}
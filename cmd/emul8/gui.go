@@ -0,0 +1,41 @@
+//go:build !nogui
+
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8"
+	"emul8/chip8"
+	"emul8/gui"
+)
+
+// runGUI runs a program in a window until the window is closed.
+func runGUI(opts []chip8.Option, breakpoints []chip8.Breakpoint, rom []byte, cyclesPerFrame int) error {
+	m := emul8.NewMachine(opts...)
+	m.SetCyclesPerFrame(cyclesPerFrame)
+
+	for _, bp := range breakpoints {
+		m.Debugger().Add(bp)
+	}
+
+	if err := m.Load(rom); err != nil {
+		return err
+	}
+	gui.Run(m)
+	return nil
+}
@@ -17,30 +17,157 @@
 package main
 
 import (
-	"emul8"
+	"emul8/chip8"
+	"emul8/octo"
+	"errors"
+	"flag"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 )
 
+// Exit statuses shared by the commands.
+const (
+	exitOK      = 0
+	exitFailure = 1 // The program faulted, or a check failed.
+	exitError   = 2 // The command could not do its work.
+	exitStopped = 3 // A breakpoint was hit.
+)
+
+// commands maps the subcommands of emul8 to their implementations. Without a
+// known command, the arguments are those of run.
+var commands = map[string]func(args []string){
+	"run":         runCommand,
+	"asm":         asmCommand,
+	"disasm":      disasmCommand,
+	"conformance": conformanceCommand,
+	"bench":       benchCommand,
+	"recompile":   recompileCommand,
+	"tui":         tuiCommand,
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("must specify file")
+	log.SetFlags(0)
+	log.SetPrefix("emul8: ")
+
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			cmd(args[1:])
+			return
+		}
+	}
+	runCommand(args)
+}
+
+// fatal reports an error that stops a command from doing its work.
+func fatal(v ...any) {
+	log.Print(v...)
+	os.Exit(exitError)
+}
+
+// defaultQuirks names the quirks preset used for each mode when none is given.
+var defaultQuirks = map[chip8.Mode]string{
+	chip8.ModeCHIP8:  "default",
+	chip8.ModeSCHIP:  "schip11",
+	chip8.ModeXOCHIP: "xochip",
+}
+
+// engineOptions returns the options of the named engine.
+func engineOptions(name string) []chip8.Option {
+	opts, ok := chip8.Engines[name]
+	if !ok {
+		fatal("unknown engine: " + name)
+	}
+	return opts
+}
+
+// machineFlags are the flags of the commands that run a program.
+type machineFlags struct {
+	mode        string
+	quirks      string
+	seed        []chip8.Option
+	breakpoints []chip8.Breakpoint
+}
+
+func (m *machineFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&m.mode, "mode", "chip8", "execution mode: chip8, schip, xochip")
+	fs.StringVar(&m.quirks, "quirks", "", "quirks preset: default, vip, chip48, schip10, schip11, xochip (default depends on mode)")
+
+	fs.Func("seed", "seed for the random number generator (default random)", func(s string) error {
+		seed, err := strconv.ParseUint(s, 0, 64)
+		if err != nil {
+			return err
+		}
+		m.seed = []chip8.Option{chip8.WithSeed(seed)}
+		return nil
+	})
+
+	fs.Func("break", "breakpoint, such as \"pc 2A0\" or \"if V3 == 10\" (repeatable)", func(s string) error {
+		bp, err := chip8.ParseBreakpoint(s)
+		if err != nil {
+			return err
+		}
+		m.breakpoints = append(m.breakpoints, bp)
+		return nil
+	})
+}
+
+// options returns the processor options selected by the flags.
+func (m *machineFlags) options() ([]chip8.Option, error) {
+	mode, ok := chip8.Modes[m.mode]
+	if !ok {
+		return nil, errors.New("unknown mode: " + m.mode)
 	}
-	name := os.Args[1]
 
-	var e emul8.Emulator
+	name := m.quirks
+	if name == "" {
+		name = defaultQuirks[mode]
+	}
+
+	quirks, ok := chip8.QuirksPresets[name]
+	if !ok {
+		return nil, errors.New("unknown quirks preset: " + name)
+	}
+
+	return append([]chip8.Option{chip8.WithMode(mode), chip8.WithQuirks(quirks)}, m.seed...), nil
+}
 
+func readFile(name string) []byte {
 	f, err := os.Open(name)
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
+	defer f.Close()
 
 	b, err := io.ReadAll(f)
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
+	return b
+}
+
+// loadROM reads a ROM, compiling it first when it is an Octo program.
+func loadROM(name string) []byte {
+	b := readFile(name)
+	if filepath.Ext(name) != ".8o" {
+		return b
+	}
+
+	rom, err := octo.Compile(b)
+	if err != nil {
+		fatal(name + ":" + err.Error())
+	}
+	return rom
+}
 
-	e.Load(b)
-	e.Run()
+// writeOutput writes b to the named file, or to standard output for "-".
+func writeOutput(name string, b []byte) error {
+	if name == "-" {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(name, b, 0o644)
 }
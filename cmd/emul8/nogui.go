@@ -0,0 +1,30 @@
+//go:build nogui
+
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8/chip8"
+	"errors"
+)
+
+// Builds tagged nogui leave out Fyne and PortAudio, so that the headless
+// commands can be built on machines without graphics or audio libraries.
+func runGUI(opts []chip8.Option, breakpoints []chip8.Breakpoint, rom []byte, cyclesPerFrame int) error {
+	return errors.New("built without a GUI; use -headless")
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8/chip8"
+	"emul8/recompile"
+	"flag"
+	"os"
+	"path/filepath"
+)
+
+func recompileCommand(args []string) {
+	fs := flag.NewFlagSet("recompile", flag.ExitOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: emul8 recompile [flags] rom\n"))
+		fs.PrintDefaults()
+	}
+
+	output := fs.String("o", "-", "file to write the Go source to, or - for standard output")
+	pkg := fs.String("package", "main", "name of the package to write; main also runs the program in a window")
+	mode := fs.String("mode", "chip8", "execution mode: chip8, schip, xochip")
+	quirks := fs.String("quirks", "", "quirks preset: default, vip, chip48, schip10, schip11, xochip (default depends on mode)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	if *quirks == "" {
+		*quirks = defaultQuirks[chip8.Modes[*mode]]
+	}
+
+	src, err := recompile.Translate(loadROM(fs.Arg(0)), recompile.Options{
+		Package: *pkg,
+		Source:  filepath.Base(fs.Arg(0)),
+		Mode:    *mode,
+		Quirks:  *quirks,
+	})
+	if err != nil {
+		fatal(err)
+	}
+	if err := writeOutput(*output, src); err != nil {
+		fatal(err)
+	}
+}
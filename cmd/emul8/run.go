@@ -0,0 +1,168 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8/chip8"
+	"emul8/headless"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+)
+
+func runCommand(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: emul8 [run] [flags] rom\n"))
+		fs.PrintDefaults()
+	}
+
+	var m machineFlags
+	m.register(fs)
+
+	engine := fs.String("engine", "cached", "execution engine: interpreter, cached, recompiler, or lockstep to check the recompiler against the interpreter")
+	traceName := fs.String("trace", "", "write every executed instruction to this file")
+	headlessMode := fs.Bool("headless", false, "run without a window, keyboard or audio")
+	cycles := fs.Int("cycles", 0, "headless: stop after this many instructions")
+	frames := fs.Int("frames", 0, "headless: stop after this many frames")
+	cyclesPerFrame := fs.Int("cycles-per-frame", chip8.DefaultCyclesPerFrame, "instructions run in each frame, between timer ticks")
+	keyScript := fs.String("keys", "", "headless: key events such as \"30:5,60:A+,90:A-\"")
+	asciiName := fs.String("ascii", "", "headless: write the final display as text to this file, or - for stdout")
+	pngName := fs.String("png", "", "headless: write the final display as a PNG image to this file")
+	scale := fs.Int("scale", 1, "headless: size of each pixel in the PNG image")
+	jsonName := fs.String("json", "", "headless: write the final state as JSON to this file, or - for stdout")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fatal("must specify file")
+	}
+	rom := loadROM(fs.Arg(0))
+
+	opts, err := m.options()
+	if err != nil {
+		fatal(err)
+	}
+	opts = append(opts, engineOptions(*engine)...)
+
+	var tracer *chip8.TraceWriter
+	if *traceName != "" {
+		traceFile, err := os.Create(*traceName)
+		if err != nil {
+			fatal(err)
+		}
+		defer traceFile.Close()
+
+		tracer = chip8.NewTraceWriter(traceFile)
+		opts = append(opts, chip8.WithTracer(tracer))
+	}
+
+	status := exitOK
+	if *headlessMode {
+		if *cycles <= 0 && *frames <= 0 {
+			fatal("headless runs need -cycles or -frames")
+		}
+
+		keys, err := headless.ParseKeyScript(*keyScript)
+		if err != nil {
+			fatal(err)
+		}
+
+		status = runHeadless(opts, m.breakpoints, rom, headless.Options{
+			Cycles:         *cycles,
+			Frames:         *frames,
+			CyclesPerFrame: *cyclesPerFrame,
+			Keys:           keys,
+		}, dumps{ascii: *asciiName, png: *pngName, scale: *scale, json: *jsonName})
+	} else if err := runGUI(opts, m.breakpoints, rom, *cyclesPerFrame); err != nil {
+		fatal(err)
+	}
+
+	if tracer != nil {
+		if err := tracer.Flush(); err != nil {
+			fatal(err)
+		}
+	}
+
+	if status != exitOK {
+		os.Exit(status)
+	}
+}
+
+// dumps names the files written at the end of a headless run.
+type dumps struct {
+	ascii string
+	png   string
+	scale int
+	json  string
+}
+
+// runHeadless runs a program to completion, returning the exit status.
+func runHeadless(opts []chip8.Option, breakpoints []chip8.Breakpoint, rom []byte, o headless.Options, d dumps) int {
+	p := chip8.NewProcessor(opts...)
+	if err := p.Load(rom); err != nil {
+		fatal(err)
+	}
+
+	dbg := chip8.NewDebugger(p)
+	for _, bp := range breakpoints {
+		dbg.Add(bp)
+	}
+
+	result := headless.Run(dbg, o)
+
+	if d.ascii != "" {
+		if err := writeOutput(d.ascii, []byte(headless.ASCII(p))); err != nil {
+			fatal(err)
+		}
+	}
+
+	if d.png != "" {
+		f, err := os.Create(d.png)
+		if err != nil {
+			fatal(err)
+		}
+
+		err = headless.WritePNG(f, p, d.scale)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	if d.json != "" {
+		b, err := json.MarshalIndent(headless.NewState(p, result), "", "  ")
+		if err != nil {
+			fatal(err)
+		}
+		if err := writeOutput(d.json, append(b, '\n')); err != nil {
+			fatal(err)
+		}
+	}
+
+	switch result.Outcome {
+	case headless.Faulted:
+		log.Print(result.Err)
+		return exitFailure
+	case headless.Stopped:
+		log.Print(result.Stop)
+		return exitStopped
+	}
+	return exitOK
+}
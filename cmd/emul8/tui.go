@@ -0,0 +1,64 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"emul8"
+	"emul8/chip8"
+	"emul8/tui"
+	"flag"
+	"os"
+)
+
+func tuiCommand(args []string) {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: emul8 tui [flags] rom\n"))
+		fs.PrintDefaults()
+	}
+
+	var m machineFlags
+	m.register(fs)
+
+	engine := fs.String("engine", "cached", "execution engine: interpreter, cached, recompiler, or lockstep to check the recompiler against the interpreter")
+	cyclesPerFrame := fs.Int("cycles-per-frame", chip8.DefaultCyclesPerFrame, "instructions run in each frame, between timer ticks")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	rom := loadROM(fs.Arg(0))
+
+	opts, err := m.options()
+	if err != nil {
+		fatal(err)
+	}
+
+	machine := emul8.NewMachine(append(opts, engineOptions(*engine)...)...)
+	machine.SetCyclesPerFrame(*cyclesPerFrame)
+	for _, bp := range m.breakpoints {
+		machine.Debugger().Add(bp)
+	}
+
+	if err := machine.Load(rom); err != nil {
+		fatal(err)
+	}
+	if err := tui.Run(machine); err != nil {
+		fatal(err)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package conformance runs a corpus of small programs and compares the
+// display each one leaves with a golden copy, so that a change in the
+// behavior of any instruction or quirk shows up as a changed screen.
+//
+// A corpus holds three things:
+//
+//	cases.txt       one case per line: name, ROM, mode, quirks, frames, keys
+//	roms/*.hex      programs written as annotated hexadecimal
+//	golden/*.txt    the expected display of each case, as text
+package conformance
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"emul8/chip8"
+	"emul8/headless"
+	"errors"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+//go:embed corpus
+var corpus embed.FS
+
+// Corpus is the corpus built into the package.
+var Corpus, _ = fs.Sub(corpus, "corpus")
+
+// Seed seeds the random number generator of every case.
+const Seed = 1
+
+var ErrNoGolden = errors.New("no golden display")
+
+type Case struct {
+	Name   string
+	ROM    string
+	Mode   chip8.Mode
+	Quirks string
+	Frames int
+	Keys   []headless.KeyEvent
+}
+
+// GoldenPath returns the path of the case's golden display in the corpus.
+func (c Case) GoldenPath() string {
+	return path.Join("golden", c.Name+".txt")
+}
+
+// Cases reads the cases of a corpus. Blank lines and lines starting with #
+// are skipped.
+func Cases(fsys fs.FS) ([]Case, error) {
+	b, err := fs.ReadFile(fsys, "cases.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []Case
+
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		c, err := parseCase(text)
+		if err != nil {
+			return nil, errors.New("cases.txt:" + strconv.Itoa(line) + ": " + err.Error())
+		}
+		cases = append(cases, c)
+	}
+	return cases, s.Err()
+}
+
+func parseCase(line string) (Case, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 5 {
+		return Case{}, errors.New("want name, rom, mode, quirks and frames")
+	}
+
+	c := Case{Name: fields[0], ROM: fields[1], Quirks: fields[3]}
+
+	mode, ok := chip8.Modes[fields[2]]
+	if !ok {
+		return c, errors.New("unknown mode: " + fields[2])
+	}
+	c.Mode = mode
+
+	if _, ok := chip8.QuirksPresets[c.Quirks]; !ok {
+		return c, errors.New("unknown quirks preset: " + c.Quirks)
+	}
+
+	frames, err := strconv.Atoi(fields[4])
+	if err != nil || frames <= 0 {
+		return c, errors.New("bad frame count: " + fields[4])
+	}
+	c.Frames = frames
+
+	c.Keys, err = headless.ParseKeyScript(strings.Join(fields[5:], " "))
+	return c, err
+}
+
+// ParseHex reads a program written as hexadecimal bytes. Everything after a
+// semicolon is a comment, and a number followed by a colon gives the address
+// of the next byte, which must match where the byte falls in the program.
+func ParseHex(src []byte) ([]byte, error) {
+	var rom []byte
+
+	s := bufio.NewScanner(bytes.NewReader(src))
+	for line := 1; s.Scan(); line++ {
+		code, _, _ := strings.Cut(s.Text(), ";")
+
+		for _, field := range strings.Fields(code) {
+			if addr, ok := strings.CutSuffix(field, ":"); ok {
+				a, err := strconv.ParseUint(addr, 16, 16)
+				if err != nil || int(a) != int(chip8.ProgramStartAddress)+len(rom) {
+					return nil, errors.New("line " + strconv.Itoa(line) + ": address " + addr + " out of place")
+				}
+				continue
+			}
+
+			b, err := strconv.ParseUint(field, 16, 8)
+			if err != nil || len(field) != 2 {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": bad byte " + field)
+			}
+			rom = append(rom, byte(b))
+		}
+	}
+	return rom, s.Err()
+}
+
+// Run runs the case and returns the display it leaves, as drawn by
+// headless.ASCII. Options, such as the execution engine, are applied after
+// those of the case.
+func (c Case) Run(fsys fs.FS, opts ...chip8.Option) (string, error) {
+	src, err := fs.ReadFile(fsys, path.Join("roms", c.ROM+".hex"))
+	if err != nil {
+		return "", err
+	}
+
+	rom, err := ParseHex(src)
+	if err != nil {
+		return "", errors.New(c.ROM + ".hex: " + err.Error())
+	}
+
+	p := chip8.NewProcessor(append([]chip8.Option{
+		chip8.WithMode(c.Mode),
+		chip8.WithQuirks(chip8.QuirksPresets[c.Quirks]),
+		chip8.WithSeed(Seed),
+	}, opts...)...)
+	if err := p.Load(rom); err != nil {
+		return "", err
+	}
+
+	result := headless.Run(chip8.NewDebugger(p), headless.Options{Frames: c.Frames, Keys: c.Keys})
+	if result.Outcome == headless.Faulted {
+		return "", result.Err
+	}
+	return headless.ASCII(p), nil
+}
+
+type Result struct {
+	Case Case
+	Got  string
+	Want string
+	Err  error // Set when the case could not be run or has no golden.
+}
+
+func (r Result) Passed() bool {
+	return r.Err == nil && r.Got == r.Want
+}
+
+// Diff describes where the display differs from the golden.
+func (r Result) Diff() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+
+	got, want := strings.Split(r.Got, "\n"), strings.Split(r.Want, "\n")
+	if len(got) != len(want) {
+		return "display has " + strconv.Itoa(len(got)-1) + " rows, golden has " + strconv.Itoa(len(want)-1)
+	}
+
+	var b strings.Builder
+	for y := range got {
+		if got[y] != want[y] {
+			b.WriteString("row " + strconv.Itoa(y) + "\n  got  " + got[y] + "\n  want " + want[y] + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Check runs a case and compares its display with the golden.
+func Check(fsys fs.FS, c Case, opts ...chip8.Option) Result {
+	r := Result{Case: c}
+
+	r.Got, r.Err = c.Run(fsys, opts...)
+	if r.Err != nil {
+		return r
+	}
+
+	want, err := fs.ReadFile(fsys, c.GoldenPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		err = ErrNoGolden
+	}
+	r.Want, r.Err = string(want), err
+	return r
+}
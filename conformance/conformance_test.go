@@ -0,0 +1,124 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conformance
+
+import (
+	"emul8/chip8"
+	"emul8/headless"
+	"errors"
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden displays in corpus from the current behavior")
+
+// TestCorpus runs every case of the corpus under every engine, as the
+// displays must not depend on how instructions are run.
+func TestCorpus(t *testing.T) {
+	cases, err := Cases(Corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		for _, c := range cases {
+			got, err := c.Run(Corpus)
+			if err != nil {
+				t.Fatal(c.Name + ": " + err.Error())
+			}
+			if err := os.WriteFile(filepath.Join("corpus", filepath.FromSlash(c.GoldenPath())), []byte(got), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	engines := make([]string, 0, len(chip8.Engines))
+	for name := range chip8.Engines {
+		engines = append(engines, name)
+	}
+	slices.Sort(engines)
+
+	for _, engine := range engines {
+		t.Run(engine, func(t *testing.T) {
+			for _, c := range cases {
+				t.Run(c.Name, func(t *testing.T) {
+					if r := Check(Corpus, c, chip8.Engines[engine]...); !r.Passed() {
+						t.Error(r.Diff())
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestLockstep runs every program of the corpus under each quirks preset,
+// with keys pressed in turn, checking the recompiler against the
+// interpreter. A program may fault under quirks it was not written for, but
+// the two must never disagree.
+func TestLockstep(t *testing.T) {
+	cases, err := Cases(Corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []headless.KeyEvent
+	for frame := 0; frame < 300; frame += 10 {
+		key := uint8(frame / 10 % chip8.KeyCount)
+		keys = append(keys, headless.KeyEvent{Frame: frame, Key: key, Down: true}, headless.KeyEvent{Frame: frame + 5, Key: key})
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range cases {
+		if seen[c.ROM] {
+			continue
+		}
+		seen[c.ROM] = true
+
+		for _, quirks := range slices.Sorted(maps.Keys(chip8.QuirksPresets)) {
+			t.Run(c.ROM+"/"+quirks, func(t *testing.T) {
+				c.Quirks, c.Frames, c.Keys = quirks, 300, keys
+
+				_, err := c.Run(Corpus, chip8.WithLockstep())
+				var divergence *chip8.Divergence
+				if errors.As(err, &divergence) {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	rom, err := ParseHex([]byte("200: 60 2A ; LD V0, 2A\n\n202: 12 02\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x60, 0x2A, 0x12, 0x02}; !slices.Equal(rom, want) {
+		t.Errorf("ParseHex() = % X, want % X", rom, want)
+	}
+
+	for _, src := range []string{"200: 60 2A\n206: 12 02", "60 2A3", "6"} {
+		if _, err := ParseHex([]byte(src)); err == nil {
+			t.Errorf("ParseHex(%q) succeeded", src)
+		}
+	}
+}
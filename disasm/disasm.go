@@ -0,0 +1,287 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package disasm turns ROM images back into source for package asm.
+//
+// Rather than decoding every word, it follows the program from its start
+// through jumps, calls and skips, so that only reachable instructions are
+// written as code. Everything else, such as sprites, is written as DB bytes.
+// Jump and call targets, and the addresses loaded into I, are given labels.
+// Assembling the output gives back the same ROM.
+package disasm
+
+import (
+	"emul8/byteconv"
+	"emul8/chip8"
+	"strings"
+)
+
+// bytesPerLine is the most bytes written on one DB line.
+const bytesPerLine = 8
+
+// target is the way an address is referred to, which chooses the prefix of
+// its label.
+type target uint8
+
+const (
+	targetData target = iota + 1
+	targetJump
+	targetCall
+)
+
+var prefixes = []string{
+	targetData: "dat_",
+	targetJump: "loc_",
+	targetCall: "sub_",
+}
+
+type disassembler struct {
+	rom     []byte
+	code    map[uint16]int // The size of each reachable instruction.
+	targets map[uint16]target
+	labels  map[uint16]string
+}
+
+// Disassemble returns source for a ROM loaded at chip8.ProgramStartAddress.
+func Disassemble(rom []byte) string {
+	d := newDisassembler(rom)
+	return d.write(d.lines())
+}
+
+// Trace follows a ROM loaded at chip8.ProgramStartAddress as Disassemble
+// does. It returns the size of each reachable instruction by address, and
+// the labels Disassemble gives the addresses referred to.
+func Trace(rom []byte) (code map[uint16]int, labels map[uint16]string) {
+	d := newDisassembler(rom)
+	d.label(d.lines())
+	return d.code, d.labels
+}
+
+func newDisassembler(rom []byte) *disassembler {
+	d := &disassembler{
+		rom:     rom,
+		code:    make(map[uint16]int),
+		targets: make(map[uint16]target),
+		labels:  make(map[uint16]string),
+	}
+	d.trace(chip8.ProgramStartAddress)
+	return d
+}
+
+// word returns the word at addr, or false when it is outside the ROM.
+func (d *disassembler) word(addr uint16) (uint16, bool) {
+	off := int(addr) - int(chip8.ProgramStartAddress)
+	if off < 0 || off+1 >= len(d.rom) {
+		return 0, false
+	}
+	return uint16(d.rom[off])<<8 | uint16(d.rom[off+1]), true
+}
+
+// size returns the size of the instruction at addr, or 0 when there is none.
+func (d *disassembler) size(addr uint16) int {
+	w, ok := d.word(addr)
+	if !ok {
+		return 0
+	}
+	in, ok := chip8.Decode(chip8.Opcode(w))
+	if !ok {
+		return 0
+	}
+
+	if in.Size > 2 {
+		if _, ok := d.word(addr + uint16(in.Size) - 2); !ok {
+			return 0
+		}
+	}
+	return in.Size
+}
+
+// address returns the address operand of the instruction at addr, which
+// is either in the opcode or in the word after it.
+func (d *disassembler) address(in *chip8.Instruction, addr uint16) (uint16, bool) {
+	w, _ := d.word(addr)
+	for _, k := range in.Operands {
+		switch k {
+		case chip8.OperandAddress:
+			return w & 0x0FFF, true
+		case chip8.OperandLong:
+			return d.word(addr + 2)
+		}
+	}
+	return 0, false
+}
+
+func (d *disassembler) refer(addr uint16, t target) {
+	d.targets[addr] = max(d.targets[addr], t)
+}
+
+// trace follows every path through the program from start, recording the
+// instructions it reaches.
+func (d *disassembler) trace(start uint16) {
+	work := []uint16{start}
+
+	for len(work) > 0 {
+		addr := work[len(work)-1]
+		work = work[:len(work)-1]
+
+	path:
+		for {
+			if _, seen := d.code[addr]; seen {
+				break
+			}
+
+			size := d.size(addr)
+			if size == 0 {
+				break
+			}
+			d.code[addr] = size
+
+			w, _ := d.word(addr)
+			in, _ := chip8.Decode(chip8.Opcode(w))
+			next := addr + uint16(size)
+			target, ok := d.address(in, addr)
+
+			if ok && in.Writes&chip8.UseI != 0 {
+				d.refer(target, targetData)
+			}
+
+			switch in.Flow {
+			case chip8.FlowReturn, chip8.FlowHalt:
+				break path
+			case chip8.FlowJump, chip8.FlowJumpIndirect:
+				// The target of JP V0 is not known, but it is most often a
+				// table of jumps starting at NNN.
+				d.refer(target, targetJump)
+				work = append(work, target)
+				break path
+			case chip8.FlowCall:
+				d.refer(target, targetCall)
+				work = append(work, target)
+			case chip8.FlowSkip:
+				// A skip passes over the whole of a long load.
+				work = append(work, next+uint16(max(2, d.size(next))))
+			}
+			addr = next
+		}
+	}
+}
+
+// line is an instruction, or a run of data bytes, at an address.
+type line struct {
+	addr uint16
+	size int
+	code bool
+}
+
+// lines lays the ROM out as instructions and data. An instruction that
+// overlaps one before it is written as data, and data is split wherever an
+// address is referred to, so that it can be labeled.
+func (d *disassembler) lines() []line {
+	var lines []line
+
+	end := int(chip8.ProgramStartAddress) + len(d.rom)
+	for addr := int(chip8.ProgramStartAddress); addr < end; {
+		if size, ok := d.code[uint16(addr)]; ok {
+			lines = append(lines, line{addr: uint16(addr), size: size, code: true})
+			addr += size
+			continue
+		}
+
+		l := line{addr: uint16(addr), size: 1}
+		for addr+l.size < end && l.size < bytesPerLine {
+			next := uint16(addr + l.size)
+			if _, ok := d.code[next]; ok {
+				break
+			}
+			if _, ok := d.targets[next]; ok {
+				break
+			}
+			l.size++
+		}
+		lines = append(lines, l)
+		addr += l.size
+	}
+	return lines
+}
+
+// label names the lines whose addresses are referred to.
+func (d *disassembler) label(lines []line) {
+	for _, l := range lines {
+		if t, ok := d.targets[l.addr]; ok {
+			d.labels[l.addr] = prefixes[t] + chip8.FormatAddress(l.addr)
+		}
+	}
+}
+
+func (d *disassembler) write(lines []line) string {
+	d.label(lines)
+
+	var b strings.Builder
+	for _, l := range lines {
+		if label, ok := d.labels[l.addr]; ok {
+			b.WriteString(label + ":\n")
+		}
+
+		off := int(l.addr) - int(chip8.ProgramStartAddress)
+		raw := d.rom[off : off+l.size]
+
+		var text, note string
+		if l.code {
+			text, note = d.instruction(raw)
+		} else {
+			hex := make([]string, len(raw))
+			for i, v := range raw {
+				hex[i] = byteconv.Btoh([]byte{v}, 2)
+			}
+			text = "DB " + strings.Join(hex, ", ")
+		}
+
+		b.WriteString("\t" + text)
+		if l.code {
+			b.WriteString(strings.Repeat(" ", max(1, 24-len(text))) + "; " + chip8.FormatAddress(l.addr) + note)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// instruction writes the instruction in raw with its address operand as a
+// label, where there is one, along with a note for its comment.
+func (d *disassembler) instruction(raw []byte) (string, string) {
+	op := chip8.Opcode(uint16(raw[0])<<8 | uint16(raw[1]))
+	in, _ := chip8.Decode(op)
+
+	// The interpreter ignores some bits, such as the low nibble of 9XYN,
+	// but the assembler cannot write them.
+	if op&in.Ignored() != 0 {
+		return "DW " + byteconv.Btoh(raw[:2], 4), " " + op.String()
+	}
+
+	str := op.Format(func(addr uint16) string {
+		if label, ok := d.labels[addr]; ok {
+			return label
+		}
+		return byteconv.Btoh(byteconv.U16tob(addr), 3)
+	})
+	if in.Size == 4 {
+		long := uint16(raw[2])<<8 | uint16(raw[3])
+		if label, ok := d.labels[long]; ok {
+			return str + " " + label, ""
+		}
+		return str + " " + byteconv.Btoh(raw[2:], 4), ""
+	}
+	return str, ""
+}
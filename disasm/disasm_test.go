@@ -0,0 +1,157 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disasm_test
+
+import (
+	"bytes"
+	"emul8/asm"
+	"emul8/conformance"
+	"emul8/disasm"
+	"flag"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// features exercises what the corpus does not: calls, computed jumps,
+// skips over long loads, unreachable code, data loaded into I and an
+// opcode with bits the assembler cannot write.
+var features = []byte{
+	0x22, 0x0A, // 200: CALL sub_20A
+	0xB2, 0x12, // 202: JP V0, loc_212
+	0x35, 0x00, // 204: SE V5, 00 (unreachable)
+	0x00, 0xE0, // 206: CLS (unreachable)
+	0xF0, 0xF0, // 208: data
+	0x3A, 0x01, // 20A: SE VA, 01
+	0xF0, 0x00, // 20C: LD I, LONG dat_218
+	0x02, 0x18,
+	0x9A, 0xB1, // 210: 9AB1, SNE VA, VB with the low nibble set
+	0x00, 0xEE, // 212: RET
+	0x12, 0x00, // 214: JP loc_200
+	0x12, 0x00, // 216: JP 200 (unreachable)
+	0xAA, 0x55, // 218: sprite
+}
+
+// roms returns the corpus ROMs and features by name.
+func roms(t *testing.T) map[string][]byte {
+	t.Helper()
+
+	roms := map[string][]byte{"features": features}
+
+	names, err := fs.Glob(conformance.Corpus, "roms/*.hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		src, err := fs.ReadFile(conformance.Corpus, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rom, err := conformance.ParseHex(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roms[strings.TrimSuffix(path.Base(name), ".hex")] = rom
+	}
+	return roms
+}
+
+func TestGolden(t *testing.T) {
+	for name, rom := range roms(t) {
+		t.Run(name, func(t *testing.T) {
+			got := disasm.Disassemble(rom)
+
+			golden := filepath.Join("testdata", name+".s")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("Disassemble() =\n%s\nwant\n%s", got, want)
+			}
+
+			again, err := asm.Assemble([]byte(got))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(again, rom) {
+				t.Error("the disassembly does not assemble to the ROM")
+			}
+		})
+	}
+}
+
+// TestRoundTripRandom assembles the disassembly of random ROMs, which
+// decode to every instruction, in every arrangement, along with words that
+// are not instructions.
+func TestRoundTripRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	for i := range 500 {
+		rom := make([]byte, 1+r.IntN(256))
+		for j := range rom {
+			rom[j] = byte(r.Uint32())
+		}
+
+		source := disasm.Disassemble(rom)
+		again, err := asm.Assemble([]byte(source))
+		if err != nil {
+			t.Fatalf("ROM %d: %v in\n%s", i, err, source)
+		}
+		if !bytes.Equal(again, rom) {
+			t.Fatalf("ROM %d: round trip gave\n% X\nwant\n% X\nthrough\n%s", i, again, rom, source)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	code, labels := disasm.Trace(features)
+
+	for addr, size := range map[uint16]int{0x200: 2, 0x202: 2, 0x20A: 2, 0x20C: 4, 0x210: 2, 0x212: 2, 0x214: 2} {
+		if code[addr] != size {
+			t.Errorf("code[%03X] = %d, want %d", addr, code[addr], size)
+		}
+	}
+	for _, addr := range []uint16{0x204, 0x206, 0x208, 0x216, 0x218} {
+		if _, ok := code[addr]; ok {
+			t.Errorf("%03X is traced as code", addr)
+		}
+	}
+
+	want := map[uint16]string{0x200: "loc_200", 0x20A: "sub_20A", 0x212: "loc_212", 0x218: "dat_218"}
+	for addr, label := range want {
+		if labels[addr] != label {
+			t.Errorf("labels[%03X] = %q, want %q", addr, labels[addr], label)
+		}
+	}
+	if len(labels) != len(want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+}
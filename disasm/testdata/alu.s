@@ -0,0 +1,118 @@
+	LD VB, 00               ; 200
+	LD VC, 00               ; 202
+	LD VF, 07               ; 204
+	LD V0, FF               ; 206
+	ADD V0, 02              ; 208
+	LD V8, VF               ; 20A
+	LD VA, V0               ; 20C
+	CALL sub_2C8            ; 20E
+	LD VA, V8               ; 210
+	CALL sub_2C8            ; 212
+	LD V1, 3C               ; 214
+	LD V0, V1               ; 216
+	LD VA, V0               ; 218
+	CALL sub_2C8            ; 21A
+	LD VF, 07               ; 21C
+	LD V0, 5A               ; 21E
+	LD V1, 0F               ; 220
+	OR V0, V1               ; 222
+	LD V8, VF               ; 224
+	LD VA, V0               ; 226
+	CALL sub_2C8            ; 228
+	LD VA, V8               ; 22A
+	CALL sub_2C8            ; 22C
+	LD VF, 07               ; 22E
+	LD V0, 5A               ; 230
+	AND V0, V1              ; 232
+	LD V8, VF               ; 234
+	LD VA, V0               ; 236
+	CALL sub_2C8            ; 238
+	LD VA, V8               ; 23A
+	CALL sub_2C8            ; 23C
+	LD VF, 07               ; 23E
+	LD V0, 5A               ; 240
+	XOR V0, V1              ; 242
+	LD V8, VF               ; 244
+	LD VA, V0               ; 246
+	CALL sub_2C8            ; 248
+	LD VA, V8               ; 24A
+	CALL sub_2C8            ; 24C
+	LD V0, F0               ; 24E
+	LD V1, 20               ; 250
+	ADD V0, V1              ; 252
+	LD V8, VF               ; 254
+	LD VA, V0               ; 256
+	CALL sub_2C8            ; 258
+	LD VA, V8               ; 25A
+	CALL sub_2C8            ; 25C
+	LD V0, 10               ; 25E
+	ADD V0, V1              ; 260
+	LD V8, VF               ; 262
+	LD VA, V0               ; 264
+	CALL sub_2C8            ; 266
+	LD VA, V8               ; 268
+	CALL sub_2C8            ; 26A
+	LD V0, 30               ; 26C
+	LD V1, 10               ; 26E
+	SUB V0, V1              ; 270
+	LD V8, VF               ; 272
+	LD VA, V0               ; 274
+	CALL sub_2C8            ; 276
+	LD VA, V8               ; 278
+	CALL sub_2C8            ; 27A
+	LD V0, 10               ; 27C
+	LD V1, 30               ; 27E
+	SUB V0, V1              ; 280
+	LD V8, VF               ; 282
+	LD VA, V0               ; 284
+	CALL sub_2C8            ; 286
+	LD VA, V8               ; 288
+	CALL sub_2C8            ; 28A
+	LD V0, 10               ; 28C
+	LD V1, 30               ; 28E
+	SUBN V0, V1             ; 290
+	LD V8, VF               ; 292
+	LD VA, V0               ; 294
+	CALL sub_2C8            ; 296
+	LD VA, V8               ; 298
+	CALL sub_2C8            ; 29A
+	LD V0, 05               ; 29C
+	LD V1, 0C               ; 29E
+	SHR V0, V1              ; 2A0
+	LD V8, VF               ; 2A2
+	LD VA, V0               ; 2A4
+	CALL sub_2C8            ; 2A6
+	LD VA, V8               ; 2A8
+	CALL sub_2C8            ; 2AA
+	LD V0, 81               ; 2AC
+	LD V1, 03               ; 2AE
+	SHL V0, V1              ; 2B0
+	LD V8, VF               ; 2B2
+	LD VA, V0               ; 2B4
+	CALL sub_2C8            ; 2B6
+	LD VA, V8               ; 2B8
+	CALL sub_2C8            ; 2BA
+	LD VF, 02               ; 2BC
+	LD V1, FF               ; 2BE
+	ADD VF, V1              ; 2C0
+	LD VA, VF               ; 2C2
+	CALL sub_2C8            ; 2C4
+loc_2C6:
+	JP loc_2C6              ; 2C6
+sub_2C8:
+	LD VD, VA               ; 2C8
+	SHR VD, VD              ; 2CA
+	SHR VD, VD              ; 2CC
+	SHR VD, VD              ; 2CE
+	SHR VD, VD              ; 2D0
+	LD F, VD                ; 2D2
+	DRW VB, VC, 05          ; 2D4
+	ADD VB, 05              ; 2D6
+	LD F, VA                ; 2D8
+	DRW VB, VC, 05          ; 2DA
+	ADD VB, 06              ; 2DC
+	SE VB, 37               ; 2DE
+	RET                     ; 2E0
+	LD VB, 00               ; 2E2
+	ADD VC, 06              ; 2E4
+	RET                     ; 2E6
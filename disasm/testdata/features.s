@@ -0,0 +1,14 @@
+loc_200:
+	CALL sub_20A            ; 200
+	JP V0, loc_212          ; 202
+	DB 35, 00, 00, E0, F0, F0
+sub_20A:
+	SE VA, 01               ; 20A
+	LD I, LONG dat_218      ; 20C
+	DW 9AB1                 ; 210 SNE VA, VB
+loc_212:
+	RET                     ; 212
+	JP loc_200              ; 214
+	DB 12, 00
+dat_218:
+	DB AA, 55
@@ -0,0 +1,94 @@
+	JP loc_208              ; 200
+loc_202:
+	LD V4, 00               ; 202
+	LD V4, 02               ; 204
+	JP loc_27C              ; 206
+loc_208:
+	LD VB, 00               ; 208
+	LD VC, 00               ; 20A
+	LD V1, 05               ; 20C
+	LD V2, 05               ; 20E
+	LD V3, 06               ; 210
+	LD V0, 01               ; 212
+	SE V1, 05               ; 214
+	LD V0, 02               ; 216
+	LD VA, V0               ; 218
+	CALL sub_28C            ; 21A
+	LD V0, 01               ; 21C
+	SE V1, 06               ; 21E
+	LD V0, 02               ; 220
+	LD VA, V0               ; 222
+	CALL sub_28C            ; 224
+	LD V0, 01               ; 226
+	SNE V1, 06              ; 228
+	LD V0, 02               ; 22A
+	LD VA, V0               ; 22C
+	CALL sub_28C            ; 22E
+	LD V0, 01               ; 230
+	SNE V1, 05              ; 232
+	LD V0, 02               ; 234
+	LD VA, V0               ; 236
+	CALL sub_28C            ; 238
+	LD V0, 01               ; 23A
+	SE V1, V2               ; 23C
+	LD V0, 02               ; 23E
+	LD VA, V0               ; 240
+	CALL sub_28C            ; 242
+	LD V0, 01               ; 244
+	SE V1, V3               ; 246
+	LD V0, 02               ; 248
+	LD VA, V0               ; 24A
+	CALL sub_28C            ; 24C
+	LD V0, 01               ; 24E
+	SNE V1, V3              ; 250
+	LD V0, 02               ; 252
+	LD VA, V0               ; 254
+	CALL sub_28C            ; 256
+	LD V0, 01               ; 258
+	SNE V1, V2              ; 25A
+	LD V0, 02               ; 25C
+	LD VA, V0               ; 25E
+	CALL sub_28C            ; 260
+	LD V7, 00               ; 262
+	JP loc_268              ; 264
+	DB 67, FF
+loc_268:
+	LD VA, V7               ; 268
+	CALL sub_28C            ; 26A
+	LD V6, 00               ; 26C
+	CALL sub_282            ; 26E
+	LD VA, V6               ; 270
+	CALL sub_28C            ; 272
+	LD V4, 44               ; 274
+	LD V0, 02               ; 276
+	LD V2, 04               ; 278
+	JP V0, loc_202          ; 27A
+loc_27C:
+	LD VA, V4               ; 27C
+	CALL sub_28C            ; 27E
+loc_280:
+	JP loc_280              ; 280
+sub_282:
+	CALL sub_288            ; 282
+	ADD V6, 10              ; 284
+	RET                     ; 286
+sub_288:
+	ADD V6, 02              ; 288
+	RET                     ; 28A
+sub_28C:
+	LD VD, VA               ; 28C
+	SHR VD, VD              ; 28E
+	SHR VD, VD              ; 290
+	SHR VD, VD              ; 292
+	SHR VD, VD              ; 294
+	LD F, VD                ; 296
+	DRW VB, VC, 05          ; 298
+	ADD VB, 05              ; 29A
+	LD F, VA                ; 29C
+	DRW VB, VC, 05          ; 29E
+	ADD VB, 06              ; 2A0
+	SE VB, 37               ; 2A2
+	RET                     ; 2A4
+	LD VB, 00               ; 2A6
+	ADD VC, 06              ; 2A8
+	RET                     ; 2AA
@@ -0,0 +1,54 @@
+	LD VB, 00               ; 200
+	LD VC, 00               ; 202
+	RND V0, FF              ; 204
+	LD VA, V0               ; 206
+	CALL sub_242            ; 208
+	RND V0, 0F              ; 20A
+	LD VA, V0               ; 20C
+	CALL sub_242            ; 20E
+	LD V0, 05               ; 210
+	LD ST, V0               ; 212
+	LD V0, 03               ; 214
+	LD DT, V0               ; 216
+	LD V2, 00               ; 218
+loc_21A:
+	ADD V2, 01              ; 21A
+	LD V1, DT               ; 21C
+	SE V1, 00               ; 21E
+	JP loc_21A              ; 220
+	LD VA, V2               ; 222
+	CALL sub_242            ; 224
+	LD V5, 05               ; 226
+loc_228:
+	SKP V5                  ; 228
+	JP loc_228              ; 22A
+	LD V0, DT               ; 22C
+	LD VA, V5               ; 22E
+	CALL sub_242            ; 230
+loc_232:
+	SKNP V5                 ; 232
+	JP loc_232              ; 234
+	LD VA, V5               ; 236
+	CALL sub_242            ; 238
+	LD V6, K                ; 23A
+	LD VA, V6               ; 23C
+	CALL sub_242            ; 23E
+loc_240:
+	JP loc_240              ; 240
+sub_242:
+	LD VD, VA               ; 242
+	SHR VD, VD              ; 244
+	SHR VD, VD              ; 246
+	SHR VD, VD              ; 248
+	SHR VD, VD              ; 24A
+	LD F, VD                ; 24C
+	DRW VB, VC, 05          ; 24E
+	ADD VB, 05              ; 250
+	LD F, VA                ; 252
+	DRW VB, VC, 05          ; 254
+	ADD VB, 06              ; 256
+	SE VB, 37               ; 258
+	RET                     ; 25A
+	LD VB, 00               ; 25C
+	ADD VC, 06              ; 25E
+	RET                     ; 260
@@ -0,0 +1,33 @@
+	HIGH                    ; 200
+	LD I, dat_21C           ; 202
+	LD V0, 00               ; 204
+	DRW V0, V0, 08          ; 206
+	LOW                     ; 208
+	LD V0, 10               ; 20A
+	DRW V0, V0, 08          ; 20C
+	SCD 2                   ; 20E
+	SCR                     ; 210
+	LD VB, 00               ; 212
+	LD VC, 00               ; 214
+	LD VA, V0               ; 216
+	CALL sub_224            ; 218
+	EXIT                    ; 21A
+dat_21C:
+	DB FF, 81, 81, 81, 81, 81, 81, FF
+sub_224:
+	LD VD, VA               ; 224
+	SHR VD, VD              ; 226
+	SHR VD, VD              ; 228
+	SHR VD, VD              ; 22A
+	SHR VD, VD              ; 22C
+	LD F, VD                ; 22E
+	DRW VB, VC, 05          ; 230
+	ADD VB, 05              ; 232
+	LD F, VA                ; 234
+	DRW VB, VC, 05          ; 236
+	ADD VB, 06              ; 238
+	SE VB, 37               ; 23A
+	RET                     ; 23C
+	LD VB, 00               ; 23E
+	ADD VC, 06              ; 240
+	RET                     ; 242
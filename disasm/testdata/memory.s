@@ -0,0 +1,69 @@
+	LD VB, 00               ; 200
+	LD VC, 00               ; 202
+	LD I, dat_25E           ; 204
+	LD V2, [I]              ; 206
+	LD VA, V0               ; 208
+	CALL sub_268            ; 20A
+	LD VA, V2               ; 20C
+	CALL sub_268            ; 20E
+	LD I, dat_25E           ; 210
+	LD V2, [I]              ; 212
+	LD V0, [I]              ; 214
+	LD VA, V0               ; 216
+	CALL sub_268            ; 218
+	LD I, dat_264           ; 21A
+	LD V0, A1               ; 21C
+	LD V1, B2               ; 21E
+	LD [I], V1              ; 220
+	LD V0, C3               ; 222
+	LD [I], V0              ; 224
+	LD I, dat_264           ; 226
+	LD V3, [I]              ; 228
+	LD VA, V0               ; 22A
+	CALL sub_268            ; 22C
+	LD VA, V1               ; 22E
+	CALL sub_268            ; 230
+	LD VA, V2               ; 232
+	CALL sub_268            ; 234
+	LD VA, V3               ; 236
+	CALL sub_268            ; 238
+	LD I, dat_25E           ; 23A
+	LD V0, 03               ; 23C
+	ADD I, V0               ; 23E
+	LD V0, [I]              ; 240
+	LD VA, V0               ; 242
+	CALL sub_268            ; 244
+	LD V0, 9C               ; 246
+	LD I, dat_264           ; 248
+	LD B, V0                ; 24A
+	LD I, dat_264           ; 24C
+	LD V2, [I]              ; 24E
+	LD VA, V0               ; 250
+	CALL sub_268            ; 252
+	LD VA, V1               ; 254
+	CALL sub_268            ; 256
+	LD VA, V2               ; 258
+	CALL sub_268            ; 25A
+loc_25C:
+	JP loc_25C              ; 25C
+dat_25E:
+	DB 11, 22, 33, 44, 55, 66
+dat_264:
+	DB 00, 00, 00, 00
+sub_268:
+	LD VD, VA               ; 268
+	SHR VD, VD              ; 26A
+	SHR VD, VD              ; 26C
+	SHR VD, VD              ; 26E
+	SHR VD, VD              ; 270
+	LD F, VD                ; 272
+	DRW VB, VC, 05          ; 274
+	ADD VB, 05              ; 276
+	LD F, VA                ; 278
+	DRW VB, VC, 05          ; 27A
+	ADD VB, 06              ; 27C
+	SE VB, 37               ; 27E
+	RET                     ; 280
+	LD VB, 00               ; 282
+	ADD VC, 06              ; 284
+	RET                     ; 286
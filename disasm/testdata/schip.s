@@ -0,0 +1,52 @@
+	HIGH                    ; 200
+	LD I, dat_23C           ; 202
+	LD V0, 08               ; 204
+	LD V1, 08               ; 206
+	DRW V0, V1, 00          ; 208
+	LD V2, 08               ; 20A
+	LD HF, V2               ; 20C
+	LD V0, 20               ; 20E
+	DRW V0, V1, 0A          ; 210
+	SCD 4                   ; 212
+	SCR                     ; 214
+	SCR                     ; 216
+	SCL                     ; 218
+	LD V0, 12               ; 21A
+	LD V1, 34               ; 21C
+	LD V2, 56               ; 21E
+	LD R, V2                ; 220
+	LD V0, 00               ; 222
+	LD V1, 00               ; 224
+	LD V2, 00               ; 226
+	LD V2, R                ; 228
+	LD VB, 00               ; 22A
+	LD VC, 28               ; 22C
+	LD VA, V0               ; 22E
+	CALL sub_25C            ; 230
+	LD VA, V1               ; 232
+	CALL sub_25C            ; 234
+	LD VA, V2               ; 236
+	CALL sub_25C            ; 238
+	EXIT                    ; 23A
+dat_23C:
+	DB FF, FF, 80, 01, 80, 01, 80, 01
+	DB 80, 01, 80, 01, 80, 01, 80, 01
+	DB 80, 01, 80, 01, 80, 01, 80, 01
+	DB 80, 01, 80, 01, 80, 01, FF, FF
+sub_25C:
+	LD VD, VA               ; 25C
+	SHR VD, VD              ; 25E
+	SHR VD, VD              ; 260
+	SHR VD, VD              ; 262
+	SHR VD, VD              ; 264
+	LD F, VD                ; 266
+	DRW VB, VC, 05          ; 268
+	ADD VB, 05              ; 26A
+	LD F, VA                ; 26C
+	DRW VB, VC, 05          ; 26E
+	ADD VB, 06              ; 270
+	SE VB, 37               ; 272
+	RET                     ; 274
+	LD VB, 00               ; 276
+	ADD VC, 06              ; 278
+	RET                     ; 27A
@@ -0,0 +1,9 @@
+	LD I, dat_208           ; 200
+	LD V0, 04               ; 202
+	DRW V0, V0, 00          ; 204
+	EXIT                    ; 206
+dat_208:
+	DB FF, FF, 80, 01, 80, 01, 80, 01
+	DB 80, 01, 80, 01, 80, 01, 80, 01
+	DB 80, 01, 80, 01, 80, 01, 80, 01
+	DB 80, 01, 80, 01, 80, 01, FF, FF
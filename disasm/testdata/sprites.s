@@ -0,0 +1,51 @@
+	LD VB, 00               ; 200
+	LD VC, 00               ; 202
+	LD I, dat_23E           ; 204
+	DRW VB, VC, 08          ; 206
+	CLS                     ; 208
+	LD V0, 3C               ; 20A
+	LD V1, 1C               ; 20C
+	DRW V0, V1, 08          ; 20E
+	LD V0, 00               ; 210
+	DRW V0, V1, 08          ; 212
+	LD V1, 00               ; 214
+	LD V0, 3C               ; 216
+	DRW V0, V1, 08          ; 218
+	LD V0, 5C               ; 21A
+	LD V1, 2E               ; 21C
+	DRW V0, V1, 08          ; 21E
+	LD V0, 10               ; 220
+	LD V1, 10               ; 222
+	DRW V0, V1, 08          ; 224
+	LD VA, VF               ; 226
+	CALL sub_246            ; 228
+	LD I, dat_23E           ; 22A
+	DRW V0, V1, 08          ; 22C
+	LD VA, VF               ; 22E
+	CALL sub_246            ; 230
+	LD V0, 0A               ; 232
+	LD F, V0                ; 234
+	LD V0, 30               ; 236
+	LD V1, 08               ; 238
+	DRW V0, V1, 05          ; 23A
+loc_23C:
+	JP loc_23C              ; 23C
+dat_23E:
+	DB FF, 81, 81, 81, 81, 81, 81, FF
+sub_246:
+	LD VD, VA               ; 246
+	SHR VD, VD              ; 248
+	SHR VD, VD              ; 24A
+	SHR VD, VD              ; 24C
+	SHR VD, VD              ; 24E
+	LD F, VD                ; 250
+	DRW VB, VC, 05          ; 252
+	ADD VB, 05              ; 254
+	LD F, VA                ; 256
+	DRW VB, VC, 05          ; 258
+	ADD VB, 06              ; 25A
+	SE VB, 37               ; 25C
+	RET                     ; 25E
+	LD VB, 00               ; 260
+	ADD VC, 06              ; 262
+	RET                     ; 264
@@ -0,0 +1,75 @@
+	PLANE 1                 ; 200
+	LD I, dat_266           ; 202
+	LD V0, 04               ; 204
+	LD V1, 10               ; 206
+	DRW V0, V1, 08          ; 208
+	PLANE 2                 ; 20A
+	LD V0, 08               ; 20C
+	DRW V0, V1, 08          ; 20E
+	PLANE 3                 ; 210
+	LD I, dat_26E           ; 212
+	LD V0, 0C               ; 214
+	DRW V0, V1, 08          ; 216
+	PLANE 1                 ; 218
+	SCU 4                   ; 21A
+	LD V1, 0A               ; 21C
+	LD V2, 0B               ; 21E
+	LD V3, 0C               ; 220
+	LD I, dat_27E           ; 222
+	LD [I], V1-V3           ; 224
+	LD I, dat_27E           ; 226
+	LD V6-V4, [I]           ; 228
+	LD V7, 01               ; 22A
+	SE V7, 01               ; 22C
+	LD I, LONG 0000         ; 22E
+	LD V7, 02               ; 232
+	LD I, LONG 1000         ; 234
+	LD V8, 5A               ; 238
+	LD [I], V8              ; 23A
+	LD V8, 00               ; 23C
+	LD I, LONG 1000         ; 23E
+	LD V8, [I]              ; 242
+	LD I, dat_281           ; 244
+	AUDIO                   ; 246
+	LD V9, 70               ; 248
+	PITCH V9                ; 24A
+	LD VB, 00               ; 24C
+	LD VC, 00               ; 24E
+	LD VA, V4               ; 250
+	CALL sub_291            ; 252
+	LD VA, V5               ; 254
+	CALL sub_291            ; 256
+	LD VA, V6               ; 258
+	CALL sub_291            ; 25A
+	LD VA, V7               ; 25C
+	CALL sub_291            ; 25E
+	LD VA, V8               ; 260
+	CALL sub_291            ; 262
+	EXIT                    ; 264
+dat_266:
+	DB FF, 81, 81, 81, 81, 81, 81, FF
+dat_26E:
+	DB 3C, 3C, 3C, 3C, 3C, 3C, 3C, 3C
+	DB 00, 00, 3C, 3C, 3C, 3C, 00, 00
+dat_27E:
+	DB 00, 00, 00
+dat_281:
+	DB FF, 00, FF, 00, FF, 00, FF, 00
+	DB FF, 00, FF, 00, FF, 00, FF, 00
+sub_291:
+	LD VD, VA               ; 291
+	SHR VD, VD              ; 293
+	SHR VD, VD              ; 295
+	SHR VD, VD              ; 297
+	SHR VD, VD              ; 299
+	LD F, VD                ; 29B
+	DRW VB, VC, 05          ; 29D
+	ADD VB, 05              ; 29F
+	LD F, VA                ; 2A1
+	DRW VB, VC, 05          ; 2A3
+	ADD VB, 06              ; 2A5
+	SE VB, 37               ; 2A7
+	RET                     ; 2A9
+	LD VB, 00               ; 2AB
+	ADD VC, 06              ; 2AD
+	RET                     ; 2AF
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package emul8 runs a processor for a frontend, which shows the display
+// and debugging panels, takes key presses and plays the sound. Frontends
+// implement Video, Input and Audio; package gui is the windowed one.
+package emul8
+
+import (
+	"context"
+	"emul8/chip8"
+	"image/color"
+)
+
+// Keypad maps the keys of a QWERTY keyboard to the keys of the hex keypad,
+// keeping the keypad's 4x4 layout.
+var Keypad = map[rune]uint8{
+	'1': 0x1, '2': 0x2, '3': 0x3, '4': 0xC,
+	'q': 0x4, 'w': 0x5, 'e': 0x6, 'r': 0xD,
+	'a': 0x7, 's': 0x8, 'd': 0x9, 'f': 0xE,
+	'z': 0xA, 'x': 0x0, 'c': 0xB, 'v': 0xF,
+}
+
+// Palette maps each combination of the two bitplanes to a color.
+var Palette = [4]color.Color{
+	color.Black,
+	color.White,
+	color.RGBA{R: 0xAA, G: 0xAA, B: 0xAA, A: 0xFF},
+	color.RGBA{R: 0x55, G: 0x55, B: 0x55, A: 0xFF},
+}
+
+// LogLength is the number of instructions and messages kept in a frame.
+const LogLength = 16
+
+// Registers are the registers shown beside the display.
+type Registers struct {
+	V     [chip8.RegisterCount]uint8
+	PC    uint16
+	I     uint16
+	Stack int // The depth of the stack.
+}
+
+// Frame is a snapshot of the machine, taken once a frame and whenever a
+// request changes the machine. Nothing changes a frame once it has been
+// presented, so frames may share slices with the frames before them.
+type Frame struct {
+	Pixels    []uint8 // As chip8.Processor.Display returns them.
+	Width     int
+	Height    int
+	Registers Registers
+	Log       []string // The latest messages and instructions stepped through, newest first.
+}
+
+// Video presents the machine. Present is called from the goroutine running
+// the machine, and must not block it: a frontend keeps the latest frame,
+// and shows it when its UI is ready, at most once a frame.
+type Video interface {
+	Present(f *Frame)
+}
+
+// Input delivers the user's requests to the machine.
+type Input interface {
+	Events() <-chan Event
+}
+
+// Audio plays the sound of the machine: a tone, or an XO-CHIP audio
+// pattern once one is set, from Start until Stop.
+type Audio interface {
+	Start(ctx context.Context) error
+	Stop() error
+	SetPattern(pattern [chip8.PatternSize]byte, pitch uint8)
+}
+
+// EventKind is a request of the user.
+type EventKind uint8
+
+const (
+	KeyDown      EventKind = iota // Presses Key on the keypad.
+	KeyUp                         // Releases Key on the keypad.
+	Pause                         // Pauses the machine.
+	Resume                        // Resumes a paused machine.
+	TogglePause                   // Pauses or resumes the machine.
+	Step                          // Runs one instruction of a paused machine.
+	StepOver                      // Runs until the next instruction, over a call.
+	StepOut                       // Runs until the current subroutine returns.
+	StartRewind                   // Steps backwards through the recent history.
+	StopRewind                    // Stops stepping backwards.
+	ToggleRewind                  // Starts or stops stepping backwards.
+	Save                          // Saves the state to Slot.
+	Load                          // Loads the state from Slot.
+)
+
+type Event struct {
+	Kind EventKind
+	Key  uint8 // The keypad key of KeyDown and KeyUp.
+	Slot int   // The slot of Save and Load, from zero.
+}
@@ -0,0 +1,384 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package gui runs a machine in a Fyne window, with sound from package
+// sound.
+package gui
+
+import (
+	"context"
+	"emul8"
+	"emul8/byteconv"
+	"emul8/chip8"
+	"emul8/sound"
+	"image"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// keyMap maps keyboard keys to the keys of the hex keypad, as laid out by
+// emul8.Keypad.
+var keyMap = make(map[fyne.KeyName]uint8)
+
+func init() {
+	for r, hex := range emul8.Keypad {
+		keyMap[fyne.KeyName(strings.ToUpper(string(r)))] = hex
+	}
+}
+
+// rewindKey rewinds the emulation while it is held down.
+const rewindKey = fyne.KeyBackspace
+
+// saveKeys and loadKeys map hotkeys to save state slots.
+var (
+	saveKeys = map[fyne.KeyName]int{fyne.KeyF1: 0, fyne.KeyF2: 1, fyne.KeyF3: 2, fyne.KeyF4: 3}
+	loadKeys = map[fyne.KeyName]int{fyne.KeyF5: 0, fyne.KeyF6: 1, fyne.KeyF7: 2, fyne.KeyF8: 3}
+)
+
+// eventBuffer is the number of requests that can wait for the machine.
+const eventBuffer = 64
+
+// frontend is the window, as the Video and Input of a machine. The machine
+// presents frames to it from its own goroutine; the window shows the latest
+// of them once a frame, on the UI goroutine.
+type frontend struct {
+	events chan emul8.Event
+	done   <-chan struct{} // Closed once the machine stops serving events.
+	latest atomic.Pointer[emul8.Frame]
+
+	// The frame the widgets show, which only the UI goroutine touches.
+	shown *emul8.Frame
+
+	buffer  *image.RGBA
+	image   *canvas.Image
+	console *Console
+
+	registerData   []string
+	registers      binding.ExternalStringList
+	registerList   *widget.List
+	programCounter *widget.Label
+	index          *widget.Label
+	stackDepth     *widget.Label
+}
+
+// Run runs a machine in a window until the window is closed.
+func Run(m *emul8.Machine) {
+	a := app.New()
+	w := a.NewWindow("Chip-8 Emulator")
+
+	f := &frontend{
+		events: make(chan emul8.Event, eventBuffer),
+
+		// A back-buffer for the pixel data, large enough for the high
+		// resolution mode. Low resolution pixels are drawn as 2x2 blocks.
+		buffer: image.NewRGBA(image.Rect(0, 0, chip8.HiResWidth, chip8.HiResHeight)),
+
+		console:        NewConsole(9),
+		registerData:   make([]string, chip8.RegisterCount),
+		programCounter: widget.NewLabel("PC: 000"),
+		index:          widget.NewLabel("I: 000"),
+		stackDepth:     widget.NewLabel("Stack: 0"),
+	}
+
+	f.image = canvas.NewImageFromImage(f.buffer)
+	f.image.FillMode = canvas.ImageFillStretch  // Scales the grid to window size
+	f.image.ScaleMode = canvas.ImageScalePixels // Maintains "pixelated" retro look
+
+	canv, ok := w.Canvas().(desktop.Canvas) // Extension that exposes OnKeyUp event
+	if !ok {
+		panic("emulator cannot be run on mobile")
+	}
+	canv.SetOnKeyDown(f.onKeyDown)
+	canv.SetOnKeyUp(f.onKeyUp)
+
+	imageContent := container.New(
+		layout.NewGridWrapLayout(fyne.NewSize(float32(chip8.Width)*10, float32(chip8.Height)*10)),
+		f.image,
+	)
+
+	opcodeContent := container.New(
+		layout.NewGridWrapLayout(fyne.NewSize(125, (float32)(chip8.Height))),
+		f.console.Object(),
+	)
+
+	f.registers = binding.BindStringList(&f.registerData)
+
+	f.registerList = widget.NewListWithData(
+		f.registers,
+		func() fyne.CanvasObject {
+			return widget.NewLabel("template")
+		},
+		func(di binding.DataItem, obj fyne.CanvasObject) {
+			s, _ := di.(binding.String).Get()
+			obj.(*widget.Label).SetText(s)
+		},
+	)
+
+	toolbar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.MediaPlayIcon(), func() {
+			f.send(emul8.Event{Kind: emul8.Resume})
+		}),
+		widget.NewToolbarAction(theme.MediaPauseIcon(), func() {
+			f.send(emul8.Event{Kind: emul8.Pause})
+		}),
+		widget.NewToolbarAction(theme.MediaSkipNextIcon(), func() {
+			f.send(emul8.Event{Kind: emul8.Step})
+		}),
+		widget.NewToolbarAction(theme.MoveDownIcon(), func() {
+			f.send(emul8.Event{Kind: emul8.StepOver})
+		}),
+		widget.NewToolbarAction(theme.MoveUpIcon(), func() {
+			f.send(emul8.Event{Kind: emul8.StepOut})
+		}),
+		widget.NewToolbarAction(theme.MediaFastRewindIcon(), func() {
+			f.send(emul8.Event{Kind: emul8.ToggleRewind})
+		}),
+	)
+
+	hbox := container.NewHBox(layout.NewSpacer(), f.programCounter, layout.NewSpacer(), f.index, layout.NewSpacer(), f.stackDepth, layout.NewSpacer())
+
+	box := container.NewBorder(toolbar, hbox, opcodeContent, f.registerList, imageContent)
+
+	w.SetContent(box)
+
+	w.Resize(fyne.NewSize(float32(chip8.Width*10), float32(chip8.Height*10))) // 10x scale for visibility
+
+	w.SetFixedSize(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.done = ctx.Done()
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		m.Run(ctx, f, f, &sound.Beep{})
+	})
+	wg.Go(func() {
+		f.refresh(ctx)
+	})
+
+	w.ShowAndRun()
+	cancel()
+	wg.Wait()
+}
+
+// send passes a request to the machine. Requests beyond those the buffer
+// holds are dropped rather than blocking the UI, except key releases, which
+// wait for room so that no key is left held.
+func (f *frontend) send(ev emul8.Event) {
+	if ev.Kind == emul8.KeyUp {
+		select {
+		case f.events <- ev:
+		case <-f.done:
+		}
+		return
+	}
+
+	select {
+	case f.events <- ev:
+	default:
+	}
+}
+
+func (f *frontend) onKeyDown(k *fyne.KeyEvent) {
+	if k.Name == rewindKey {
+		f.send(emul8.Event{Kind: emul8.StartRewind})
+		return
+	}
+
+	if hex, ok := keyMap[k.Name]; ok {
+		f.send(emul8.Event{Kind: emul8.KeyDown, Key: hex})
+	}
+}
+
+func (f *frontend) onKeyUp(k *fyne.KeyEvent) {
+	if k.Name == rewindKey {
+		f.send(emul8.Event{Kind: emul8.StopRewind})
+		return
+	}
+
+	if k.Name == fyne.KeyP {
+		f.send(emul8.Event{Kind: emul8.TogglePause})
+		return
+	}
+
+	if k.Name == fyne.KeyN {
+		f.send(emul8.Event{Kind: emul8.Step})
+		return
+	}
+
+	if slot, ok := saveKeys[k.Name]; ok {
+		f.send(emul8.Event{Kind: emul8.Save, Slot: slot})
+		return
+	}
+
+	if slot, ok := loadKeys[k.Name]; ok {
+		f.send(emul8.Event{Kind: emul8.Load, Slot: slot})
+		return
+	}
+
+	if hex, ok := keyMap[k.Name]; ok {
+		f.send(emul8.Event{Kind: emul8.KeyUp, Key: hex})
+	}
+}
+
+func (f *frontend) Events() <-chan emul8.Event {
+	return f.events
+}
+
+// Present keeps the frame for the next refresh. Frames presented in between
+// are never shown.
+func (f *frontend) Present(frame *emul8.Frame) {
+	f.latest.Store(frame)
+}
+
+// refresh shows the latest frame once a frame, until ctx is done. A frame
+// is handed to the UI goroutine only once it has shown the one before, so
+// updates never queue up behind a busy window.
+func (f *frontend) refresh(ctx context.Context) {
+	ticker := time.NewTicker(chip8.TimerRate)
+	defer ticker.Stop()
+
+	var last *emul8.Frame
+	var pending atomic.Bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		frame := f.latest.Load()
+		if frame == nil || frame == last || pending.Load() {
+			continue
+		}
+		last = frame
+		pending.Store(true)
+		fyne.Do(func() {
+			f.show(frame)
+			pending.Store(false)
+		})
+	}
+}
+
+// show updates the widgets whose values differ from the frame shown before.
+func (f *frontend) show(frame *emul8.Frame) {
+	prev, first := f.shown, f.shown == nil
+	if first {
+		prev = &emul8.Frame{}
+	}
+	f.shown = frame
+
+	if frame.Width != prev.Width || !slices.Equal(frame.Pixels, prev.Pixels) {
+		f.draw(frame.Pixels, frame.Width)
+		f.image.Refresh()
+	}
+
+	if !slices.Equal(frame.Log, prev.Log) {
+		f.console.SetLines(frame.Log)
+	}
+
+	r, old := frame.Registers, prev.Registers
+	if first || r.V != old.V {
+		for i, v := range r.V {
+			f.registerData[i] = "V" + byteconv.Btoh([]byte{uint8(i)}, 1) + ": " + byteconv.Btoh([]byte{v}, 2)
+		}
+		_ = f.registers.Reload()
+		f.registerList.Refresh()
+	}
+	if r.PC != old.PC {
+		f.programCounter.SetText("PC: " + byteconv.Btoh(byteconv.U16tob(r.PC), 3))
+	}
+	if r.I != old.I {
+		f.index.SetText("I: " + byteconv.Btoh(byteconv.U16tob(r.I), 3))
+	}
+	if r.Stack != old.Stack {
+		f.stackDepth.SetText("Stack: " + strconv.Itoa(r.Stack))
+	}
+}
+
+func (f *frontend) draw(pixels []uint8, width int) {
+	scale := chip8.HiResWidth / width
+	for i, val := range pixels {
+		x, y := (i%width)*scale, (i/width)*scale
+		c := emul8.Palette[val&0x3]
+		for dy := range scale {
+			for dx := range scale {
+				f.buffer.Set(x+dx, y+dy, c) // Directly sets pixels in the buffer
+			}
+		}
+	}
+}
+
+type Console struct {
+	capacity  int
+	container *fyne.Container
+}
+
+func NewConsole(capacity int) *Console {
+	labels := make([]fyne.CanvasObject, capacity)
+	for i := range capacity {
+		labels[i] = widget.NewLabel("")
+	}
+	return &Console{
+		capacity:  capacity,
+		container: container.NewVBox(labels...),
+	}
+}
+
+// SetLines shows lines, the newest first, as many as fit.
+func (o *Console) SetLines(lines []string) {
+	for i, obj := range o.container.Objects {
+		text := ""
+		if i < len(lines) {
+			text = lines[i]
+		}
+		obj.(*widget.Label).SetText(text)
+	}
+}
+
+func (o *Console) Object() fyne.CanvasObject {
+	return o.container
+}
+
+type Content struct {
+	fyne.CanvasObject
+	size fyne.Size
+}
+
+func NewContent(o fyne.CanvasObject, size fyne.Size) *Content {
+	return &Content{
+		o,
+		size,
+	}
+}
+
+func (c *Content) MinSize() fyne.Size {
+	return c.size
+}
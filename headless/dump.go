@@ -0,0 +1,120 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package headless
+
+import (
+	"emul8/chip8"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// asciiPixels maps each combination of the two bitplanes to a character.
+var asciiPixels = [4]byte{'.', '#', '+', '*'}
+
+// palette matches the colors of the GUI.
+var palette = color.Palette{
+	color.Black,
+	color.White,
+	color.RGBA{R: 0xAA, G: 0xAA, B: 0xAA, A: 0xFF},
+	color.RGBA{R: 0x55, G: 0x55, B: 0x55, A: 0xFF},
+}
+
+// ASCII renders the display one line per row.
+func ASCII(p *chip8.Processor) string {
+	pixels, width, height := p.Display()
+
+	var b strings.Builder
+	b.Grow((width + 1) * height)
+
+	for y := range height {
+		for _, val := range pixels[y*width : (y+1)*width] {
+			b.WriteByte(asciiPixels[val&0x3])
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// WritePNG encodes the display as a PNG image, drawing each pixel as a
+// scale by scale block.
+func WritePNG(w io.Writer, p *chip8.Processor, scale int) error {
+	pixels, width, height := p.Display()
+	scale = max(scale, 1)
+
+	img := image.NewPaletted(image.Rect(0, 0, width*scale, height*scale), palette)
+	for i, val := range pixels {
+		x, y := (i%width)*scale, (i/width)*scale
+		for dy := range scale {
+			for dx := range scale {
+				img.SetColorIndex(x+dx, y+dy, val&0x3)
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// State is the machine state reported at the end of a run, laid out for
+// encoding as JSON.
+type State struct {
+	Outcome string                   `json:"outcome"`
+	Error   string                   `json:"error,omitempty"`
+	Stop    string                   `json:"stop,omitempty"`
+	Frames  int                      `json:"frames"`
+	Cycles  uint64                   `json:"cycles"`
+	Mode    string                   `json:"mode"`
+	PC      uint16                   `json:"pc"`
+	I       uint16                   `json:"i"`
+	V       [chip8.RegisterCount]int `json:"v"` // Numbers rather than the base64 of []uint8.
+	Stack   []uint16                 `json:"stack"`
+	DT      uint8                    `json:"dt"`
+	ST      uint8                    `json:"st"`
+	HiRes   bool                     `json:"hires"`
+	Halted  bool                     `json:"halted"`
+}
+
+func NewState(p *chip8.Processor, r Result) State {
+	s := State{
+		Outcome: r.Outcome.String(),
+		Frames:  r.Frames,
+		Cycles:  p.Cycles(),
+		Mode:    p.Mode().String(),
+		PC:      p.ProgramCounter(),
+		I:       p.Index(),
+		Stack:   p.Stack(),
+		Halted:  p.Halted(),
+	}
+
+	if r.Err != nil {
+		s.Error = r.Err.Error()
+	}
+
+	if r.Stop != nil {
+		s.Stop = r.Stop.String()
+	}
+
+	for x := range s.V {
+		s.V[x] = int(p.Register(uint8(x)))
+	}
+	s.DT, s.ST = p.Timers()
+
+	_, width, _ := p.Display()
+	s.HiRes = width == chip8.HiResWidth
+	return s
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package headless
+
+import (
+	"emul8/chip8"
+	"encoding/json"
+	"testing"
+)
+
+func TestStateJSON(t *testing.T) {
+	p := chip8.NewProcessor()
+	if err := p.Load([]byte{
+		0x60, 0x2A, // LD V0, 2A
+		0x6F, 0xFF, // LD VF, FF
+		0x12, 0x04, // JP 204
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := Run(chip8.NewDebugger(p), Options{Frames: 1})
+	b, err := json.Marshal(NewState(p, r))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var state struct {
+		Outcome string `json:"outcome"`
+		V       []int  `json:"v"`
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		t.Fatalf("%v in %s", err, b)
+	}
+
+	if state.Outcome != "completed" {
+		t.Errorf("outcome = %q, want completed", state.Outcome)
+	}
+	if len(state.V) != chip8.RegisterCount {
+		t.Fatalf("len(v) = %d, want %d", len(state.V), chip8.RegisterCount)
+	}
+	if state.V[0] != 0x2A || state.V[0xF] != 0xFF {
+		t.Errorf("v[0], v[15] = %d, %d, want 42, 255", state.V[0], state.V[0xF])
+	}
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package headless runs programs without a display, keyboard or audio, for
+// batch jobs and continuous integration.
+package headless
+
+import (
+	"emul8/chip8"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrKeyScript = errors.New("malformed key script")
+
+type Options struct {
+	Cycles         int // Stop after this many instructions, when positive.
+	Frames         int // Stop after this many frames, when positive.
+	CyclesPerFrame int // Defaults to chip8.DefaultCyclesPerFrame.
+	Keys           []KeyEvent
+}
+
+type Outcome uint8
+
+const (
+	Completed Outcome = iota // The cycle or frame limit was reached.
+	Halted                   // The program exited.
+	Faulted                  // An instruction could not be executed.
+	Stopped                  // A breakpoint was hit.
+)
+
+var outcomes = []string{
+	Completed: "completed",
+	Halted:    "halted",
+	Faulted:   "faulted",
+	Stopped:   "stopped",
+}
+
+func (o Outcome) String() string {
+	return outcomes[o]
+}
+
+type Result struct {
+	Outcome Outcome
+	Frames  int         // Frames completed, each ending with a timer tick.
+	Err     error       // The fault, when the outcome is Faulted.
+	Stop    *chip8.Stop // The breakpoint, when the outcome is Stopped.
+}
+
+// Run executes the processor behind d from its current state until a limit
+// is reached, the program exits, an instruction faults or a breakpoint is
+// hit. Without limits, it runs until one of the others. Key events are
+// applied at the start of their frame.
+func Run(d *chip8.Debugger, opts Options) Result {
+	if opts.CyclesPerFrame <= 0 {
+		opts.CyclesPerFrame = chip8.DefaultCyclesPerFrame
+	}
+
+	p := d.Processor()
+	keys := sortKeys(opts.Keys)
+	var cycles int
+
+	for frame := 0; opts.Frames <= 0 || frame < opts.Frames; frame++ {
+		for len(keys) > 0 && keys[0].Frame <= frame {
+			p.SetKey(keys[0].Key, keys[0].Down)
+			keys = keys[1:]
+		}
+
+		n := opts.CyclesPerFrame
+		if opts.Cycles > 0 {
+			n = min(n, opts.Cycles-cycles)
+		}
+
+		// A run that ends early for an instruction that waits has used up
+		// its n, as the instruction would be replayed until the frame ends.
+		_, stop, err := d.Run(n)
+		cycles += n
+
+		switch {
+		case err != nil:
+			return Result{Outcome: Faulted, Frames: frame, Err: err}
+		case stop != nil:
+			return Result{Outcome: Stopped, Frames: frame, Stop: stop}
+		case p.Halted():
+			return Result{Outcome: Halted, Frames: frame}
+		}
+
+		// A frame cut short by the cycle limit does not tick the timers.
+		if n < opts.CyclesPerFrame {
+			return Result{Outcome: Completed, Frames: frame}
+		}
+		p.TickTimers()
+
+		if opts.Cycles > 0 && cycles >= opts.Cycles {
+			return Result{Outcome: Completed, Frames: frame + 1}
+		}
+	}
+	return Result{Outcome: Completed, Frames: opts.Frames}
+}
+
+// KeyEvent presses or releases a key at the start of a frame.
+type KeyEvent struct {
+	Frame int
+	Key   uint8
+	Down  bool
+}
+
+// ParseKeyScript reads key events separated by commas or spaces. Each event
+// is a frame number and a hexadecimal key:
+//
+//	30:5    press key 5 at frame 30 and release it at frame 31
+//	30:5+   press key 5 at frame 30
+//	90:5-   release key 5 at frame 90
+func ParseKeyScript(script string) ([]KeyEvent, error) {
+	var events []KeyEvent
+
+	fields := strings.FieldsFunc(script, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+
+	for _, field := range fields {
+		frame, key, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, ErrKeyScript
+		}
+
+		f, err := strconv.Atoi(frame)
+		if err != nil || f < 0 {
+			return nil, ErrKeyScript
+		}
+
+		press, release := true, true
+		if k, ok := strings.CutSuffix(key, "+"); ok {
+			key, release = k, false
+		} else if k, ok := strings.CutSuffix(key, "-"); ok {
+			key, press = k, false
+		}
+
+		k, err := strconv.ParseUint(key, 16, 4)
+		if err != nil {
+			return nil, ErrKeyScript
+		}
+
+		if press {
+			events = append(events, KeyEvent{Frame: f, Key: uint8(k), Down: true})
+		}
+		if release {
+			at := f
+			if press {
+				at++
+			}
+			events = append(events, KeyEvent{Frame: at, Key: uint8(k)})
+		}
+	}
+	return events, nil
+}
+
+// sortKeys orders events by frame, keeping the order of events in the same
+// frame.
+func sortKeys(events []KeyEvent) []KeyEvent {
+	events = slices.Clone(events)
+	slices.SortStableFunc(events, func(a, b KeyEvent) int {
+		return a.Frame - b.Frame
+	})
+	return events
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package headless
+
+import (
+	"emul8/chip8"
+	"testing"
+)
+
+func TestRunCycleLimitWhileWaiting(t *testing.T) {
+	p := chip8.NewProcessor()
+	if err := p.Load([]byte{0xF0, 0x0A}); err != nil { // LD V0, K
+		t.Fatal(err)
+	}
+
+	r := Run(chip8.NewDebugger(p), Options{Cycles: 110})
+	if r.Outcome != Completed || r.Frames != 10 {
+		t.Errorf("Run() = %v after %d frames, want completed after 10", r.Outcome, r.Frames)
+	}
+	if p.Cycles() != 0 {
+		t.Errorf("Cycles() = %d, want 0 while waiting for a key", p.Cycles())
+	}
+}
+
+func TestRunKeys(t *testing.T) {
+	p := chip8.NewProcessor(chip8.WithMode(chip8.ModeSCHIP))
+	if err := p.Load([]byte{0xF0, 0x0A, 0x00, 0xFD}); err != nil { // LD V0, K; EXIT
+		t.Fatal(err)
+	}
+
+	keys, err := ParseKeyScript("3:7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Run(chip8.NewDebugger(p), Options{Frames: 10, Keys: keys})
+	if r.Outcome != Halted || r.Frames != 3 {
+		t.Errorf("Run() = %v after %d frames, want halted after 3", r.Outcome, r.Frames)
+	}
+	if v := p.Register(0); v != 0x7 {
+		t.Errorf("V0 = %X, want 7", v)
+	}
+}
@@ -0,0 +1,434 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package emul8
+
+import (
+	"context"
+	"emul8/chip8"
+	"emul8/rewind"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// SlotCount is the number of save state slots.
+const SlotCount = 4
+
+// RewindCapacity is the number of snapshots kept for rewinding, one per
+// timer tick.
+const RewindCapacity = 10 * 60
+
+// maxLag is how far a machine may fall behind its schedule before it gives
+// up on the frames it missed, rather than running them back to back.
+const maxLag = 4 * chip8.TimerRate
+
+// Machine runs a processor through its debugger for a frontend, a frame of
+// instructions at a time, serving the user's requests between frames and
+// presenting a Frame after each.
+type Machine struct {
+	cpu *chip8.Processor
+	dbg *chip8.Debugger
+
+	cyclesPerFrame int
+
+	// The state of the run, which only the goroutine running the machine
+	// touches.
+	paused    bool
+	rewinding bool
+
+	slots   [SlotCount][]byte
+	history *rewind.Buffer
+
+	// The latest instructions and messages, as a ring of the last
+	// LogLength, and the number logged in all.
+	log    [LogLength]entry
+	logged int
+
+	// The frame last presented, and the number logged when it was taken.
+	// Frames share the parts that have not changed since.
+	last       *Frame
+	lastLogged int
+}
+
+// entry is an instruction of the log, or a message when msg is set.
+// Instructions are formatted only when a frame shows them.
+type entry struct {
+	op  chip8.Opcode
+	msg string
+}
+
+func NewMachine(opts ...chip8.Option) *Machine {
+	cpu := chip8.NewProcessor(opts...)
+	return &Machine{
+		cpu:            cpu,
+		dbg:            chip8.NewDebugger(cpu),
+		cyclesPerFrame: chip8.DefaultCyclesPerFrame,
+		history:        rewind.New(RewindCapacity),
+	}
+}
+
+// SetCyclesPerFrame sets the number of instructions run in each frame, and
+// so the speed of the machine. Below one, the default is used. It must be
+// called before Run.
+func (m *Machine) SetCyclesPerFrame(n int) {
+	if n <= 0 {
+		n = chip8.DefaultCyclesPerFrame
+	}
+	m.cyclesPerFrame = n
+}
+
+// Debugger returns the debugger the machine runs the processor through.
+// Breakpoints may be added and removed while the machine runs.
+func (m *Machine) Debugger() *chip8.Debugger {
+	return m.dbg
+}
+
+func (m *Machine) Load(b []byte) error {
+	m.cpu.Reset()
+	return m.cpu.Load(b)
+}
+
+// Run runs the machine until ctx is done, presenting it through v, serving
+// the requests of in and playing its sound through a, which may be nil.
+//
+// Frames start every chip8.TimerRate, each running the instructions of a
+// frame, ticking the timers and presenting the result once. Each frame is
+// scheduled from when the last was due rather than when it ran, so delays
+// do not add up. A paused machine sleeps until a request arrives.
+func (m *Machine) Run(ctx context.Context, v Video, in Input, a Audio) {
+	if a == nil {
+		a = silence{}
+	}
+	defer a.Stop()
+
+	m.present(v, a, chip8.Redraw)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	next := time.Now()
+	events := in.Events()
+
+	for {
+		var tick <-chan time.Time
+		if m.running() {
+			tick = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+
+			wasRunning := m.running()
+			m.serve(ev, v, a)
+			if m.paused {
+				_ = a.Stop()
+			}
+			if !wasRunning && m.running() {
+				next = time.Now()
+				timer.Reset(0)
+			}
+			continue
+		case <-tick:
+		}
+
+		if m.rewinding {
+			m.rewindFrame(v, a)
+		} else {
+			m.runFrame(v, a)
+		}
+
+		next = next.Add(chip8.TimerRate)
+		if time.Since(next) > maxLag {
+			next = time.Now()
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+// running reports whether frames are due, as they are unless the machine
+// is paused. Rewinding goes on while paused.
+func (m *Machine) running() bool {
+	return !m.paused || m.rewinding
+}
+
+// runFrame runs up to a frame of instructions and, unless the machine was
+// paused by a breakpoint, a step target or a fault along the way, ticks the
+// timers and records the state for rewinding. While debugging, instructions
+// are stepped one at a time and logged; otherwise the whole frame runs at
+// once, through the engine the processor was built with.
+func (m *Machine) runFrame(v Video, a Audio) {
+	var info uint8
+	if m.dbg.Active() {
+		info = m.stepFrame()
+	} else {
+		var stop *chip8.Stop
+		var err error
+		info, stop, err = m.dbg.RunFrame(m.cyclesPerFrame)
+		switch {
+		case err != nil:
+			m.paused = true
+			m.print(err.Error())
+		case stop != nil:
+			m.paused = true
+			m.print(stop.String())
+		}
+	}
+
+	if !m.paused {
+		m.record()
+	}
+	m.present(v, a, info)
+}
+
+// stepFrame steps through up to a frame of instructions, stopping at a
+// breakpoint, a step target or a fault, and ticks the timers unless it
+// stopped.
+func (m *Machine) stepFrame() uint8 {
+	var info uint8
+
+	for range m.cyclesPerFrame {
+		if m.cpu.Halted() {
+			break
+		}
+
+		if stop := m.dbg.Check(); stop != nil {
+			m.paused = true
+			m.print(stop.String())
+			break
+		}
+
+		info |= m.step()
+		if m.paused {
+			break
+		}
+	}
+
+	if !m.paused {
+		m.cpu.TickTimers()
+	}
+	return info
+}
+
+// rewindFrame steps backwards through the history at the rate it was
+// recorded, one snapshot a frame.
+func (m *Machine) rewindFrame(v Video, a Audio) {
+	_ = a.Stop()
+	if m.rewind() {
+		m.present(v, a, chip8.Redraw)
+	}
+}
+
+// step runs the next instruction, even if it has a breakpoint on it, and
+// logs it. A fault, or a stop reached by the instruction, pauses the
+// machine.
+func (m *Machine) step() uint8 {
+	// Fetch errors are reported by Step as a fault.
+	opcode, _ := m.cpu.OpcodeAt(m.cpu.ProgramCounter())
+
+	info, stop, err := m.dbg.Step()
+	if err != nil {
+		// Pause on the faulting instruction so the machine can be inspected.
+		m.paused = true
+		m.print(err.Error())
+		return 0
+	}
+
+	m.append(entry{op: opcode})
+
+	if stop != nil {
+		m.paused = true
+		m.print(stop.String())
+	}
+	return info
+}
+
+// present shows the machine after instructions with the given info, and
+// sounds while the sound timer runs, unless the machine is paused or
+// rewinding.
+func (m *Machine) present(v Video, a Audio, info uint8) {
+	m.publish(v, info)
+
+	if _, sound := m.cpu.Timers(); sound > 0 && !m.paused && !m.rewinding {
+		if pattern, pitch, ok := m.cpu.AudioPattern(); ok {
+			a.SetPattern(pattern, pitch)
+		}
+		_ = a.Start(context.Background())
+	} else {
+		_ = a.Stop()
+	}
+}
+
+// publish presents a frame of the machine after instructions with the given
+// info. The display is copied only when they redrew it, and the log only
+// when something was logged.
+func (m *Machine) publish(v Video, info uint8) {
+	f := &Frame{Registers: m.registers()}
+
+	if m.last == nil || info&chip8.Redraw != 0 {
+		pixels, width, height := m.cpu.Display()
+		f.Pixels, f.Width, f.Height = slices.Clone(pixels), width, height
+	} else {
+		f.Pixels, f.Width, f.Height = m.last.Pixels, m.last.Width, m.last.Height
+	}
+
+	if m.last == nil || m.logged != m.lastLogged {
+		f.Log = m.lines()
+	} else {
+		f.Log = m.last.Log
+	}
+
+	m.last, m.lastLogged = f, m.logged
+	v.Present(f)
+}
+
+// serve carries out a request of the user. Save states are served even
+// while paused.
+func (m *Machine) serve(ev Event, v Video, a Audio) {
+	switch ev.Kind {
+	case KeyDown, KeyUp:
+		m.cpu.SetKey(ev.Key, ev.Kind == KeyDown)
+	case Pause:
+		m.paused = true
+	case Resume:
+		m.paused = false
+	case TogglePause:
+		m.paused = !m.paused
+	case Step:
+		// A single step runs only while paused, between frames.
+		if m.paused && !m.rewinding && !m.cpu.Halted() {
+			m.present(v, a, m.step())
+		}
+	case StepOver:
+		m.dbg.StepOver()
+		m.paused = false
+	case StepOut:
+		m.dbg.StepOut()
+		m.paused = false
+	case StartRewind:
+		m.rewinding = true
+	case StopRewind:
+		m.rewinding = false
+	case ToggleRewind:
+		m.rewinding = !m.rewinding
+	case Save:
+		msg := "Saved slot " + strconv.Itoa(ev.Slot+1)
+		if err := m.saveState(ev.Slot); err != nil {
+			msg = err.Error()
+		}
+		m.print(msg)
+		m.publish(v, 0)
+	case Load:
+		msg := "Loaded slot " + strconv.Itoa(ev.Slot+1)
+		if err := m.loadState(ev.Slot); err != nil {
+			msg = err.Error()
+		}
+		m.print(msg)
+		m.present(v, a, chip8.Redraw)
+	}
+}
+
+func (m *Machine) registers() Registers {
+	r := Registers{
+		PC:    m.cpu.ProgramCounter(),
+		I:     m.cpu.Index(),
+		Stack: m.cpu.StackDepth(),
+	}
+	for i := range r.V {
+		r.V[i] = m.cpu.Register(uint8(i))
+	}
+	return r
+}
+
+// print logs a message.
+func (m *Machine) print(msg string) {
+	m.append(entry{msg: msg})
+}
+
+func (m *Machine) append(e entry) {
+	m.log[m.logged%LogLength] = e
+	m.logged++
+}
+
+// lines returns the log, newest first.
+func (m *Machine) lines() []string {
+	lines := make([]string, 0, min(m.logged, LogLength))
+	for i := m.logged - 1; i >= 0 && i >= m.logged-LogLength; i-- {
+		e := m.log[i%LogLength]
+		if e.msg != "" {
+			lines = append(lines, e.msg)
+		} else {
+			lines = append(lines, e.op.String())
+		}
+	}
+	return lines
+}
+
+func (m *Machine) saveState(slot int) error {
+	if slot < 0 || slot >= SlotCount {
+		return errors.New("no slot " + strconv.Itoa(slot+1))
+	}
+
+	state, err := m.cpu.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	m.slots[slot] = state
+	return nil
+}
+
+func (m *Machine) loadState(slot int) error {
+	if slot < 0 || slot >= SlotCount {
+		return errors.New("no slot " + strconv.Itoa(slot+1))
+	}
+
+	state := m.slots[slot]
+	if state == nil {
+		return errors.New("slot " + strconv.Itoa(slot+1) + " is empty")
+	}
+	return m.cpu.UnmarshalBinary(state)
+}
+
+func (m *Machine) record() {
+	state, err := m.cpu.MarshalBinary()
+	if err != nil {
+		return
+	}
+	m.history.Push(state)
+}
+
+// rewind restores the most recent snapshot, reporting whether there was one.
+func (m *Machine) rewind() bool {
+	state, ok := m.history.Pop()
+	if !ok {
+		return false
+	}
+	return m.cpu.UnmarshalBinary(state) == nil
+}
+
+// silence is the Audio of a machine without sound.
+type silence struct{}
+
+func (silence) Start(context.Context) error               { return nil }
+func (silence) Stop() error                               { return nil }
+func (silence) SetPattern([chip8.PatternSize]byte, uint8) {}
@@ -0,0 +1,308 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package emul8
+
+import (
+	"context"
+	"emul8/chip8"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counter adds one to V0 every frame of two instructions, after a first
+// frame that starts the sound timer.
+var counter = []byte{
+	0x61, 0xFF, // LD V1, FF
+	0xF1, 0x18, // LD ST, V1
+	0x70, 0x01, // ADD V0, 01
+	0x12, 0x04, // JP 204
+}
+
+// video keeps every frame presented.
+type video struct {
+	frames chan *Frame
+}
+
+func (v *video) Present(f *Frame) {
+	v.frames <- f
+}
+
+type input chan Event
+
+func (in input) Events() <-chan Event {
+	return in
+}
+
+// audio reports whether the machine is sounding.
+type audio struct {
+	playing atomic.Bool
+}
+
+func (a *audio) Start(ctx context.Context) error {
+	a.playing.Store(true)
+	return nil
+}
+
+func (a *audio) Stop() error {
+	a.playing.Store(false)
+	return nil
+}
+
+func (a *audio) SetPattern(pattern [chip8.PatternSize]byte, pitch uint8) {}
+
+// harness runs a machine on its own goroutine. Events are unbuffered, and
+// the machine takes the next only after serving the last, so once a second
+// event has been sent, the first has been served and its frames, along with
+// every frame before it, are in frames.
+type harness struct {
+	t      *testing.T
+	m      *Machine
+	video  *video
+	events input
+	audio  *audio
+	stop   func()
+	last   *Frame // The last frame taken.
+}
+
+// start runs a machine with a program, running cycles instructions a frame.
+// setup, when not nil, is called before the machine starts.
+func start(t *testing.T, rom []byte, cycles int, setup func(m *Machine)) *harness {
+	h := &harness{
+		t:      t,
+		m:      NewMachine(),
+		video:  &video{frames: make(chan *Frame, 4096)},
+		events: make(input),
+		audio:  &audio{},
+	}
+	h.m.SetCyclesPerFrame(cycles)
+	if err := h.m.Load(rom); err != nil {
+		t.Fatal(err)
+	}
+	if setup != nil {
+		setup(h.m)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.m.Run(ctx, h.video, h.events, h.audio)
+		close(done)
+	}()
+
+	var once atomic.Bool
+	h.stop = func() {
+		if once.CompareAndSwap(false, true) {
+			cancel()
+			<-done
+		}
+	}
+	t.Cleanup(h.stop)
+	return h
+}
+
+func (h *harness) send(ev Event) {
+	h.t.Helper()
+	select {
+	case h.events <- ev:
+	case <-time.After(time.Second):
+		h.t.Fatal("the machine did not take an event")
+	}
+}
+
+// next returns the next frame presented.
+func (h *harness) next() *Frame {
+	h.t.Helper()
+	select {
+	case f := <-h.video.frames:
+		h.last = f
+		return f
+	case <-time.After(time.Second):
+		h.t.Fatal("no frame was presented")
+		return nil
+	}
+}
+
+// drain returns the frames presented but not yet taken.
+func (h *harness) drain() []*Frame {
+	var frames []*Frame
+	for {
+		select {
+		case f := <-h.video.frames:
+			frames = append(frames, f)
+			h.last = f
+		default:
+			return frames
+		}
+	}
+}
+
+// pause pauses the machine and returns the last frame it presented.
+// Pausing twice makes sure the first has been served.
+func (h *harness) pause() *Frame {
+	h.t.Helper()
+	h.send(Event{Kind: Pause})
+	h.send(Event{Kind: Pause})
+	h.drain()
+	return h.last
+}
+
+// TestFramesPresentedOnce checks that each frame runs once and is presented
+// once, so that V0 counts up by one from frame to frame.
+func TestFramesPresentedOnce(t *testing.T) {
+	h := start(t, counter, 2, nil)
+
+	first := h.next()
+	if first.Registers.V[0] != 0 || first.Registers.PC != chip8.ProgramStartAddress {
+		t.Fatalf("the first frame shows V0=%d PC=%X, want the machine before it runs", first.Registers.V[0], first.Registers.PC)
+	}
+	if h.next().Registers.V[0] != 0 {
+		t.Fatal("the first frame of instructions changed V0")
+	}
+
+	for want := uint8(1); want <= 10; want++ {
+		if got := h.next().Registers.V[0]; got != want {
+			t.Fatalf("frame shows V0=%d, want %d", got, want)
+		}
+	}
+	if !h.audio.playing.Load() {
+		t.Error("the machine is silent while the sound timer runs")
+	}
+}
+
+// TestPauseStopsTicking checks that a paused machine runs no frames, ticks
+// no timers and stops its sound.
+func TestPauseStopsTicking(t *testing.T) {
+	h := start(t, counter, 2, nil)
+	for range 4 {
+		h.next()
+	}
+	last := h.pause()
+	if h.audio.playing.Load() {
+		t.Error("the machine sounds while paused")
+	}
+
+	time.Sleep(10 * chip8.TimerRate)
+	if frames := h.drain(); len(frames) > 0 {
+		t.Fatalf("%d frames were presented while paused", len(frames))
+	}
+
+	// The first frame ticks the sound timer from FF once, and every frame
+	// after it adds one to V0 and ticks it again.
+	h.stop()
+	if _, sound := h.m.cpu.Timers(); sound != 0xFF-(last.Registers.V[0]+1) {
+		t.Errorf("sound timer = %X after V0 reached %d, want %X", sound, last.Registers.V[0], 0xFF-(last.Registers.V[0]+1))
+	}
+}
+
+// TestStep checks that a paused machine runs one instruction for each Step,
+// presenting and logging it without running a frame.
+func TestStep(t *testing.T) {
+	h := start(t, counter, 2, func(m *Machine) { m.paused = true })
+	first := h.next()
+
+	h.send(Event{Kind: Step})
+	f := h.next()
+	if f.Registers.PC != 0x202 || f.Log[0] != "LD V1, FF" {
+		t.Fatalf("Step left PC=%X with %q logged, want 202 after LD V1, FF", f.Registers.PC, f.Log[0])
+	}
+	if f.Registers.V[1] != 0xFF || first.Registers.V[1] != 0 {
+		t.Errorf("Step left V1=%X, want FF", f.Registers.V[1])
+	}
+
+	time.Sleep(5 * chip8.TimerRate)
+	if frames := h.drain(); len(frames) > 0 {
+		t.Fatalf("%d frames were presented after a step", len(frames))
+	}
+
+	h.send(Event{Kind: Step})
+	if f := h.next(); f.Registers.PC != 0x204 || f.Log[0] != "LD ST, V1" {
+		t.Fatalf("Step left PC=%X with %q logged, want 204 after LD ST, V1", f.Registers.PC, f.Log[0])
+	}
+
+	h.stop()
+	if _, sound := h.m.cpu.Timers(); sound != 0xFF {
+		t.Errorf("sound timer = %X after stepping, want FF as it was never ticked", sound)
+	}
+}
+
+// TestSaveLoad checks that a slot brings back the machine as it was saved,
+// and that empty or missing slots are reported.
+func TestSaveLoad(t *testing.T) {
+	h := start(t, counter, 2, nil)
+	for range 3 {
+		h.next()
+	}
+	h.pause()
+
+	h.send(Event{Kind: Save, Slot: 0})
+	saved := h.next()
+	if saved.Log[0] != "Saved slot 1" {
+		t.Fatalf("Save logged %q", saved.Log[0])
+	}
+
+	h.send(Event{Kind: Resume})
+	for range 3 {
+		h.next()
+	}
+	last := h.pause()
+	if last.Registers == saved.Registers {
+		t.Fatal("the machine did not run after resuming")
+	}
+
+	h.send(Event{Kind: Load, Slot: 0})
+	loaded := h.next()
+	if loaded.Log[0] != "Loaded slot 1" || loaded.Registers != saved.Registers {
+		t.Errorf("Load logged %q with registers %+v, want %+v", loaded.Log[0], loaded.Registers, saved.Registers)
+	}
+
+	h.send(Event{Kind: Load, Slot: 1})
+	if f := h.next(); f.Log[0] != "slot 2 is empty" {
+		t.Errorf("loading an empty slot logged %q", f.Log[0])
+	}
+	h.send(Event{Kind: Save, Slot: SlotCount})
+	if f := h.next(); f.Log[0] != "no slot 5" {
+		t.Errorf("saving to a missing slot logged %q", f.Log[0])
+	}
+}
+
+// TestKeyRelease checks that the last of a burst of presses and releases
+// leaves the key released. The program waits at 200 for as long as key 0
+// is held, and moves on to 204 once it is released.
+func TestKeyRelease(t *testing.T) {
+	rom := []byte{
+		0xE0, 0xA1, // SKNP V0
+		0x12, 0x00, // JP 200
+		0x12, 0x04, // JP 204
+	}
+	h := start(t, rom, 2, func(m *Machine) { m.cpu.SetKey(0, true) })
+
+	for range 5 {
+		if pc := h.next().Registers.PC; pc != 0x200 {
+			t.Fatalf("PC=%X while the key is held", pc)
+		}
+	}
+
+	for range 100 {
+		h.send(Event{Kind: KeyDown, Key: 0})
+		h.send(Event{Kind: KeyUp, Key: 0})
+	}
+	h.drain()
+	if pc := h.next().Registers.PC; pc != 0x204 {
+		t.Errorf("PC=%X after the key was released, want 204", pc)
+	}
+}
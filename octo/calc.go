@@ -0,0 +1,135 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package octo
+
+import (
+	"math"
+)
+
+var unary = map[string]func(float64) float64{
+	"-":     func(x float64) float64 { return -x },
+	"~":     func(x float64) float64 { return float64(^int64(x)) },
+	"!":     func(x float64) float64 { return truth(x == 0) },
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"exp":   math.Exp,
+	"log":   math.Log,
+	"abs":   math.Abs,
+	"sqrt":  math.Sqrt,
+	"ceil":  math.Ceil,
+	"floor": math.Floor,
+	"sign": func(x float64) float64 {
+		switch {
+		case x > 0:
+			return 1
+		case x < 0:
+			return -1
+		}
+		return 0
+	},
+}
+
+var binary = map[string]func(x, y float64) float64{
+	"-":   func(x, y float64) float64 { return x - y },
+	"+":   func(x, y float64) float64 { return x + y },
+	"*":   func(x, y float64) float64 { return x * y },
+	"/":   func(x, y float64) float64 { return x / y },
+	"%":   math.Mod,
+	"&":   func(x, y float64) float64 { return float64(int64(x) & int64(y)) },
+	"|":   func(x, y float64) float64 { return float64(int64(x) | int64(y)) },
+	"^":   func(x, y float64) float64 { return float64(int64(x) ^ int64(y)) },
+	"<<":  func(x, y float64) float64 { return float64(int64(x) << uint64(y)) },
+	">>":  func(x, y float64) float64 { return float64(int64(x) >> uint64(y)) },
+	"pow": math.Pow,
+	"min": math.Min,
+	"max": math.Max,
+	"<":   func(x, y float64) float64 { return truth(x < y) },
+	"<=":  func(x, y float64) float64 { return truth(x <= y) },
+	"==":  func(x, y float64) float64 { return truth(x == y) },
+	"!=":  func(x, y float64) float64 { return truth(x != y) },
+	">=":  func(x, y float64) float64 { return truth(x >= y) },
+	">":   func(x, y float64) float64 { return truth(x > y) },
+}
+
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// calc reads an expression in braces and evaluates it. As in Octo, there is
+// no precedence: a chain of operators is evaluated from right to left, so
+// 2 * 3 + 4 is 14.
+func (c *compiler) calc() float64 {
+	c.expect("{")
+	v := c.expression()
+	c.expect("}")
+	return v
+}
+
+func (c *compiler) expression() float64 {
+	x := c.term()
+
+	if t := c.peek(); t.text != "}" && t.text != ")" {
+		op, ok := binary[t.text]
+		if !ok {
+			c.fail(t, "unknown operator "+t.text)
+		}
+		c.next()
+		return op(x, c.expression())
+	}
+	return x
+}
+
+func (c *compiler) term() float64 {
+	t := c.next()
+
+	if op, ok := unary[t.text]; ok {
+		return op(c.term())
+	}
+
+	switch t.text {
+	case "(":
+		v := c.expression()
+		c.expect(")")
+		return v
+	case "@":
+		addr := int(c.term())
+		if addr < 0 || addr >= len(c.rom) {
+			c.fail(t, "address out of range")
+		}
+		return float64(c.rom[addr])
+	case "HERE":
+		return float64(c.here)
+	case "PI":
+		return math.Pi
+	case "E":
+		return math.E
+	}
+
+	if n, ok := number(t.text); ok {
+		return float64(n)
+	}
+
+	if v, ok := c.constants[t.text]; ok {
+		return v
+	}
+	c.fail(t, "undefined name "+t.text+" in expression")
+	return 0
+}
@@ -0,0 +1,377 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package octo compiles programs written in Octo, the high-level assembly
+// language of the Octo CHIP-8 environment, into ROM images.
+//
+// The compiler covers the language as Octo documents it: labels, :alias,
+// :const, :calc, :byte, :pointer, :org, :unpack, :next, macros, the loop,
+// while and if structures, and the SUPER-CHIP and XO-CHIP instructions.
+// As in Octo, the first two bytes of the program jump to the label main.
+package octo
+
+import (
+	"emul8/chip8"
+	"strconv"
+	"strings"
+)
+
+// Error describes a problem at a line and column of the source, both
+// counted from one.
+type Error struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Col) + ": " + e.Msg
+}
+
+// maxExpansions bounds the macros expanded in a program, so that a macro
+// that expands to itself is reported rather than expanded forever.
+const maxExpansions = 100000
+
+// fixup is a reference to a label that had not been defined when the
+// reference was compiled.
+type fixup struct {
+	addr int
+	kind fixupKind
+	tok  token
+}
+
+type fixupKind uint8
+
+const (
+	fixupAddress fixupKind = iota // The low 12 bits of an instruction.
+	fixupLong                     // A 16-bit word.
+	fixupUnpack                   // The low bytes of v0 := and v1 :=.
+)
+
+type macro struct {
+	params []string
+	body   []token
+	calls  int
+}
+
+// loop is an open loop, waiting for again.
+type loop struct {
+	tok    token
+	start  int
+	breaks []int // The jumps out of the loop placed by while.
+}
+
+// branch is the jump of an open if block, waiting for else or end.
+type branch struct {
+	tok  token
+	addr int
+}
+
+type compiler struct {
+	tokens []token
+	pos    int
+
+	rom  []byte
+	used []bool
+	here int
+	end  int
+
+	constants map[string]float64
+	labels    map[string]bool
+	aliases   map[string]uint8
+	macros    map[string]*macro
+	fixups    map[string][]fixup
+
+	loops      []loop
+	branches   []branch
+	expansions int
+}
+
+// bailout carries an error out of the compiler.
+type bailout struct {
+	err *Error
+}
+
+func (c *compiler) fail(t token, msg string) {
+	panic(bailout{&Error{Line: t.line, Col: t.col, Msg: msg}})
+}
+
+// Compile returns the ROM image of an Octo program, which is loaded at
+// chip8.ProgramStartAddress.
+func Compile(src []byte) (rom []byte, err error) {
+	tokens, err := tokenize(string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compiler{
+		tokens:    tokens,
+		rom:       make([]byte, chip8.ExtendedMemorySize),
+		used:      make([]bool, chip8.ExtendedMemorySize),
+		here:      int(chip8.ProgramStartAddress) + 2,
+		end:       int(chip8.ProgramStartAddress) + 2,
+		constants: make(map[string]float64),
+		labels:    make(map[string]bool),
+		aliases:   make(map[string]uint8),
+		macros:    make(map[string]*macro),
+		fixups:    make(map[string][]fixup),
+	}
+
+	// The jump to main.
+	c.used[chip8.ProgramStartAddress] = true
+	c.used[chip8.ProgramStartAddress+1] = true
+
+	defer func() {
+		if r := recover(); r != nil {
+			b, ok := r.(bailout)
+			if !ok {
+				panic(r)
+			}
+			rom, err = nil, b.err
+		}
+	}()
+
+	for c.pos < len(c.tokens) {
+		c.statement()
+	}
+	c.finish()
+
+	return c.rom[chip8.ProgramStartAddress:c.end], nil
+}
+
+// finish checks that every structure was closed and every label defined,
+// and places the jump to main.
+func (c *compiler) finish() {
+	last := token{line: 1, col: 1}
+	if len(c.tokens) > 0 {
+		last = c.tokens[len(c.tokens)-1]
+	}
+
+	if len(c.loops) > 0 {
+		c.fail(c.loops[len(c.loops)-1].tok, "loop without again")
+	}
+	if len(c.branches) > 0 {
+		c.fail(c.branches[len(c.branches)-1].tok, "begin without end")
+	}
+
+	// Report the first undefined name in the source.
+	var first *fixup
+	for _, refs := range c.fixups {
+		f := refs[0]
+		if first == nil || f.tok.line < first.tok.line || f.tok.line == first.tok.line && f.tok.col < first.tok.col {
+			first = &f
+		}
+	}
+	if first != nil {
+		c.fail(first.tok, "undefined name "+first.tok.text)
+	}
+
+	main, ok := c.constants["main"]
+	if !ok || !c.labels["main"] {
+		c.fail(last, "the program has no main label")
+	}
+
+	c.rom[chip8.ProgramStartAddress] = 0x10 | byte(int(main)>>8&0x0F)
+	c.rom[chip8.ProgramStartAddress+1] = byte(main)
+}
+
+func (c *compiler) peek() token {
+	if c.pos >= len(c.tokens) {
+		return token{}
+	}
+	return c.tokens[c.pos]
+}
+
+func (c *compiler) next() token {
+	if c.pos >= len(c.tokens) {
+		last := token{line: 1, col: 1}
+		if len(c.tokens) > 0 {
+			last = c.tokens[len(c.tokens)-1]
+		}
+		c.fail(last, "unexpected end of program")
+	}
+	t := c.tokens[c.pos]
+	c.pos++
+	return t
+}
+
+func (c *compiler) expect(text string) token {
+	t := c.next()
+	if t.text != text || t.quoted {
+		c.fail(t, "expected "+text+", found "+t.text)
+	}
+	return t
+}
+
+// emit writes bytes at the current address.
+func (c *compiler) emit(t token, b ...byte) {
+	for _, v := range b {
+		if c.here >= len(c.rom) {
+			c.fail(t, "the program does not fit in memory")
+		}
+		if c.used[c.here] {
+			c.fail(t, "data overlap at address "+hex(c.here))
+		}
+		c.rom[c.here], c.used[c.here] = v, true
+		c.here++
+	}
+	c.end = max(c.end, c.here)
+}
+
+func (c *compiler) inst(t token, word uint16) {
+	c.emit(t, byte(word>>8), byte(word))
+}
+
+func hex(n int) string {
+	return "0x" + strings.ToUpper(strconv.FormatInt(int64(n), 16))
+}
+
+// define gives a name a value, as a label or a constant.
+func (c *compiler) define(t token, value float64, label bool) {
+	name := t.text
+	switch {
+	case t.quoted || name == "" || strings.HasPrefix(name, ":") || name == "{" || name == "}":
+		c.fail(t, "bad name "+name)
+	case c.isRegister(name) || isKeyword(name):
+		c.fail(t, name+" is a reserved word")
+	}
+	if _, ok := number(name); ok {
+		c.fail(t, name+" is a number")
+	}
+	if _, ok := c.constants[name]; ok {
+		c.fail(t, name+" is already defined")
+	}
+
+	c.constants[name] = value
+	c.labels[name] = label
+
+	for _, f := range c.fixups[name] {
+		c.patch(f, int(value))
+	}
+	delete(c.fixups, name)
+}
+
+// patch fills in a reference that was compiled before its label.
+func (c *compiler) patch(f fixup, value int) {
+	switch f.kind {
+	case fixupAddress:
+		if value < 0 || value > 0xFFF {
+			c.fail(f.tok, "address "+hex(value)+" does not fit in 12 bits")
+		}
+		c.rom[f.addr] = c.rom[f.addr]&0xF0 | byte(value>>8)
+		c.rom[f.addr+1] = byte(value)
+	case fixupLong:
+		c.rom[f.addr] = byte(value >> 8)
+		c.rom[f.addr+1] = byte(value)
+	case fixupUnpack:
+		c.rom[f.addr+1] |= byte(value>>8) & 0x0F
+		c.rom[f.addr+3] = byte(value)
+	}
+}
+
+// reference compiles a use of a label, which may not be defined yet. The
+// value is written as the fixup describes, at addr.
+func (c *compiler) reference(t token, addr int, kind fixupKind) {
+	if v, ok := c.constants[t.text]; ok {
+		c.patch(fixup{addr: addr, kind: kind, tok: t}, int(v))
+		return
+	}
+
+	if n, ok := number(t.text); ok {
+		c.patch(fixup{addr: addr, kind: kind, tok: t}, n)
+		return
+	}
+
+	if c.isRegister(t.text) || isKeyword(t.text) || t.quoted {
+		c.fail(t, "expected an address, found "+t.text)
+	}
+	c.fixups[t.text] = append(c.fixups[t.text], fixup{addr: addr, kind: kind, tok: t})
+}
+
+// value reads a number or the name of a constant, or an expression in
+// braces.
+func (c *compiler) value() (float64, token) {
+	t := c.peek()
+	if t.text == "{" && !t.quoted {
+		return c.calc(), t
+	}
+
+	c.next()
+	if n, ok := number(t.text); ok && !t.quoted {
+		return float64(n), t
+	}
+	if v, ok := c.constants[t.text]; ok {
+		return v, t
+	}
+	c.fail(t, "expected a value, found "+t.text)
+	return 0, t
+}
+
+// byteValue reads a value from -128 to 255.
+func (c *compiler) byteValue() byte {
+	v, t := c.value()
+	if v < -128 || v > 255 {
+		c.fail(t, "value "+t.text+" does not fit in a byte")
+	}
+	return byte(int(v))
+}
+
+func (c *compiler) nibbleValue() uint16 {
+	v, t := c.value()
+	if v < 0 || v > 15 {
+		c.fail(t, "value "+t.text+" does not fit in a nibble")
+	}
+	return uint16(v)
+}
+
+func (c *compiler) isRegister(name string) bool {
+	_, ok := c.aliases[name]
+	if !ok {
+		_, ok = register(name)
+	}
+	return ok
+}
+
+func (c *compiler) register() (uint16, token) {
+	t := c.next()
+	if x, ok := c.aliases[t.text]; ok {
+		return uint16(x), t
+	}
+	if x, ok := register(t.text); ok && !t.quoted {
+		return uint16(x), t
+	}
+	c.fail(t, "expected a register, found "+t.text)
+	return 0, t
+}
+
+var keywords = map[string]bool{
+	":=": true, "+=": true, "-=": true, "=-": true, "|=": true, "&=": true,
+	"^=": true, ">>=": true, "<<=": true, "==": true, "!=": true, "<": true,
+	">": true, "<=": true, ">=": true, "-": true, "{": true, "}": true,
+	";": true, "return": true, "clear": true, "bcd": true, "save": true,
+	"load": true, "saveflags": true, "loadflags": true, "sprite": true,
+	"jump": true, "jump0": true, "delay": true, "buzzer": true, "pitch": true,
+	"plane": true, "audio": true, "hires": true, "lores": true, "exit": true,
+	"scroll-down": true, "scroll-up": true, "scroll-left": true,
+	"scroll-right": true, "i": true, "if": true, "then": true, "begin": true,
+	"else": true, "end": true, "loop": true, "again": true, "while": true,
+	"key": true, "-key": true, "random": true, "hex": true, "bighex": true,
+	"long": true,
+}
+
+func isKeyword(name string) bool {
+	return keywords[name]
+}
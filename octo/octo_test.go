@@ -0,0 +1,139 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package octo_test
+
+import (
+	"bytes"
+	"emul8/asm"
+	"emul8/disasm"
+	"emul8/octo"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestGolden compiles each program in testdata and compares the disassembly
+// of its ROM with the .s file beside it.
+func TestGolden(t *testing.T) {
+	sources, err := filepath.Glob(filepath.Join("testdata", "*.8o"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) == 0 {
+		t.Fatal("no programs in testdata")
+	}
+
+	for _, source := range sources {
+		name := strings.TrimSuffix(filepath.Base(source), ".8o")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rom, err := octo.Compile(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := disasm.Disassemble(rom)
+
+			golden := strings.TrimSuffix(source, ".8o") + ".s"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("Compile() disassembles to\n%s\nwant\n%s", got, want)
+			}
+
+			// The golden file is itself checked against the ROM, so that an
+			// update cannot hide a change in the disassembler.
+			again, err := asm.Assemble(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(again, rom) {
+				t.Errorf("%s assembles to\n% X\nwant\n% X", golden, again, rom)
+			}
+		})
+	}
+}
+
+func TestJumpToMain(t *testing.T) {
+	rom, err := octo.Compile([]byte(": sub return\n: main sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x12, 0x04, 0x00, 0xEE, 0x22, 0x02}
+	if !bytes.Equal(rom, want) {
+		t.Errorf("Compile() = % X, want % X", rom, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{": main jump nowhere", "1:13: undefined name nowhere"},
+		{": start clear", "1:9: the program has no main label"},
+		{": main loop v0 += 1", "1:8: loop without again"},
+		{": main if v0 == 1 begin clear", "1:8: begin without end"},
+		{": main v0 := 1 again", "1:16: again without loop"},
+		{": main else", "1:8: else without begin"},
+		{": main end", "1:8: end without begin"},
+		{": main\n: main clear", "2:3: main is already defined"},
+		{":const x 1\n:const x 2\n: main", "2:8: x is already defined"},
+		{": main v0 := 256", "1:14: value 256 does not fit in a byte"},
+		{": main sprite v0 v1 16", "1:21: value 16 does not fit in a nibble"},
+		{": main\n  v0 :=", "2:6: unexpected end of program"},
+		{": main i += 3", "1:13: expected a register, found 3"},
+		{": main v0 ~= v1", "1:11: unknown operator ~="},
+		{": main if v0 key v1", "1:18: expected then or begin, found v1"},
+		{":calc y { 1 ? 2 } : main", "1:13: unknown operator ?"},
+		{`: main "text"`, "1:8: unexpected string"},
+		{":macro m { m } : main m", "1:12: too many macro expansions"},
+		{":org 0x200 : main clear", "1:19: data overlap at address 0x200"},
+	}
+
+	for _, tt := range tests {
+		_, err := octo.Compile([]byte(tt.src))
+		if err == nil {
+			t.Errorf("%q compiled", tt.src)
+			continue
+		}
+
+		var e *octo.Error
+		if !errors.As(err, &e) {
+			t.Errorf("%q: error %T is not an *Error", tt.src, err)
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%q: error %q, want %q", tt.src, err, tt.want)
+		}
+	}
+}
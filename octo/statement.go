@@ -0,0 +1,502 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package octo
+
+import (
+	"emul8/chip8"
+	"slices"
+	"strconv"
+)
+
+// statement compiles the statement at the current token.
+func (c *compiler) statement() {
+	t := c.next()
+	if t.quoted {
+		c.fail(t, "unexpected string")
+	}
+
+	if c.directive(t) {
+		return
+	}
+
+	switch t.text {
+	case "return", ";":
+		c.inst(t, 0x00EE)
+	case "clear":
+		c.inst(t, 0x00E0)
+	case "bcd":
+		x, _ := c.register()
+		c.inst(t, 0xF033|x<<8)
+	case "save", "load":
+		x, _ := c.register()
+		if c.peek().text == "-" {
+			c.next()
+			y, _ := c.register()
+			op := uint16(0x5002)
+			if t.text == "load" {
+				op = 0x5003
+			}
+			c.inst(t, op|x<<8|y<<4)
+			break
+		}
+
+		op := uint16(0xF055)
+		if t.text == "load" {
+			op = 0xF065
+		}
+		c.inst(t, op|x<<8)
+	case "saveflags":
+		x, _ := c.register()
+		c.inst(t, 0xF075|x<<8)
+	case "loadflags":
+		x, _ := c.register()
+		c.inst(t, 0xF085|x<<8)
+	case "sprite":
+		x, _ := c.register()
+		y, _ := c.register()
+		c.inst(t, 0xD000|x<<8|y<<4|c.nibbleValue())
+	case "jump":
+		c.jump(t, 0x1000)
+	case "jump0":
+		c.jump(t, 0xB000)
+	case "delay", "buzzer", "pitch":
+		c.expect(":=")
+		x, _ := c.register()
+		op := map[string]uint16{"delay": 0xF015, "buzzer": 0xF018, "pitch": 0xF03A}[t.text]
+		c.inst(t, op|x<<8)
+	case "plane":
+		c.inst(t, 0xF001|c.nibbleValue()<<8)
+	case "audio":
+		c.inst(t, 0xF002)
+	case "hires":
+		c.inst(t, 0x00FF)
+	case "lores":
+		c.inst(t, 0x00FE)
+	case "exit":
+		c.inst(t, 0x00FD)
+	case "scroll-down":
+		c.inst(t, 0x00C0|c.nibbleValue())
+	case "scroll-up":
+		c.inst(t, 0x00D0|c.nibbleValue())
+	case "scroll-left":
+		c.inst(t, 0x00FC)
+	case "scroll-right":
+		c.inst(t, 0x00FB)
+	case "i":
+		c.index(t)
+	case "if":
+		c.conditional(t)
+	case "else":
+		if len(c.branches) == 0 {
+			c.fail(t, "else without begin")
+		}
+		open := c.branches[len(c.branches)-1]
+		c.branches[len(c.branches)-1] = branch{tok: t, addr: c.here}
+		c.inst(t, 0x1000)
+		c.patch(fixup{addr: open.addr, kind: fixupAddress, tok: t}, c.here)
+	case "end":
+		if len(c.branches) == 0 {
+			c.fail(t, "end without begin")
+		}
+		open := c.branches[len(c.branches)-1]
+		c.branches = c.branches[:len(c.branches)-1]
+		c.patch(fixup{addr: open.addr, kind: fixupAddress, tok: t}, c.here)
+	case "loop":
+		c.loops = append(c.loops, loop{tok: t, start: c.here})
+	case "while":
+		if len(c.loops) == 0 {
+			c.fail(t, "while without loop")
+		}
+		c.condition(t).compile(c, true)
+		l := &c.loops[len(c.loops)-1]
+		l.breaks = append(l.breaks, c.here)
+		c.inst(t, 0x1000)
+	case "again":
+		if len(c.loops) == 0 {
+			c.fail(t, "again without loop")
+		}
+		l := c.loops[len(c.loops)-1]
+		c.loops = c.loops[:len(c.loops)-1]
+		addr := c.here
+		c.inst(t, 0x1000)
+		c.patch(fixup{addr: addr, kind: fixupAddress, tok: t}, l.start)
+		for _, addr := range l.breaks {
+			c.patch(fixup{addr: addr, kind: fixupAddress, tok: t}, c.here)
+		}
+	default:
+		c.word(t)
+	}
+}
+
+// word compiles a statement that starts with a name or a number.
+func (c *compiler) word(t token) {
+	if c.isRegister(t.text) {
+		c.pos--
+		c.assignment()
+		return
+	}
+
+	if m, ok := c.macros[t.text]; ok {
+		c.expand(t, m)
+		return
+	}
+
+	// A number is a byte of data.
+	if n, ok := number(t.text); ok {
+		if n < -128 || n > 255 {
+			c.fail(t, "value "+t.text+" does not fit in a byte")
+		}
+		c.emit(t, byte(n))
+		return
+	}
+
+	if isKeyword(t.text) {
+		c.fail(t, "unexpected "+t.text)
+	}
+
+	// Any other name calls a subroutine.
+	c.call(t, t)
+}
+
+func (c *compiler) call(t, target token) {
+	addr := c.here
+	c.inst(t, 0x2000)
+	c.reference(target, addr, fixupAddress)
+}
+
+// jump compiles an instruction that takes an address, such as a jump.
+func (c *compiler) jump(t token, op uint16) {
+	target := c.next()
+	addr := c.here
+	c.inst(t, op)
+	c.reference(target, addr, fixupAddress)
+}
+
+// index compiles the statements that set or add to i.
+func (c *compiler) index(t token) {
+	switch op := c.next(); op.text {
+	case "+=":
+		x, _ := c.register()
+		c.inst(t, 0xF01E|x<<8)
+	case ":=":
+		switch c.peek().text {
+		case "hex":
+			c.next()
+			x, _ := c.register()
+			c.inst(t, 0xF029|x<<8)
+		case "bighex":
+			c.next()
+			x, _ := c.register()
+			c.inst(t, 0xF030|x<<8)
+		case "long":
+			c.next()
+			target := c.next()
+			start := c.here
+			c.inst(t, 0xF000)
+			c.inst(t, 0x0000)
+			c.reference(target, start+2, fixupLong)
+		default:
+			c.jump(t, 0xA000)
+		}
+	default:
+		c.fail(op, "expected := or += after i, found "+op.text)
+	}
+}
+
+// assignment compiles the statements that start with a register.
+func (c *compiler) assignment() {
+	x, t := c.register()
+	op := c.next()
+
+	// The register forms of the arithmetic operators.
+	alu := map[string]uint16{
+		":=": 0x0, "|=": 0x1, "&=": 0x2, "^=": 0x3, "+=": 0x4, "-=": 0x5,
+		">>=": 0x6, "=-": 0x7, "<<=": 0xE,
+	}
+
+	code, ok := alu[op.text]
+	if !ok {
+		c.fail(op, "unknown operator "+op.text)
+	}
+
+	if c.isRegister(c.peek().text) {
+		y, _ := c.register()
+		c.inst(t, 0x8000|x<<8|y<<4|code)
+		return
+	}
+
+	switch op.text {
+	case ":=":
+		switch c.peek().text {
+		case "random":
+			c.next()
+			c.inst(t, 0xC000|x<<8|uint16(c.byteValue()))
+		case "key":
+			c.next()
+			c.inst(t, 0xF00A|x<<8)
+		case "delay":
+			c.next()
+			c.inst(t, 0xF007|x<<8)
+		default:
+			c.inst(t, 0x6000|x<<8|uint16(c.byteValue()))
+		}
+	case "+=":
+		c.inst(t, 0x7000|x<<8|uint16(c.byteValue()))
+	case "-=":
+		c.inst(t, 0x7000|x<<8|uint16(-c.byteValue()))
+	default:
+		c.fail(c.peek(), "expected a register after "+op.text)
+	}
+}
+
+// condition is the test of an if or while.
+type condition struct {
+	tok   token
+	x     uint16
+	op    string
+	y     uint16
+	isReg bool // Whether y is a register, rather than a byte.
+}
+
+var negations = map[string]string{
+	"==": "!=", "!=": "==", "<": ">=", ">=": "<", ">": "<=", "<=": ">",
+	"key": "-key", "-key": "key",
+}
+
+func (c *compiler) condition(t token) condition {
+	x, _ := c.register()
+	op := c.next()
+	if _, ok := negations[op.text]; !ok || op.quoted {
+		c.fail(op, "unknown comparison "+op.text)
+	}
+
+	cond := condition{tok: t, x: x, op: op.text}
+	if op.text == "key" || op.text == "-key" {
+		return cond
+	}
+
+	if c.isRegister(c.peek().text) {
+		cond.y, _ = c.register()
+		cond.isReg = true
+	} else {
+		cond.y = uint16(c.byteValue())
+	}
+	return cond
+}
+
+// compile writes the instructions that skip the next one when the condition
+// is false, or when it is true if negated.
+func (cond condition) compile(c *compiler, negated bool) {
+	op := cond.op
+	if negated {
+		op = negations[op]
+	}
+
+	x, y, t := cond.x, cond.y, cond.tok
+	switch op {
+	case "==":
+		if cond.isReg {
+			c.inst(t, 0x9000|x<<8|y<<4)
+		} else {
+			c.inst(t, 0x4000|x<<8|y)
+		}
+	case "!=":
+		if cond.isReg {
+			c.inst(t, 0x5000|x<<8|y<<4)
+		} else {
+			c.inst(t, 0x3000|x<<8|y)
+		}
+	case "key":
+		c.inst(t, 0xE0A1|x<<8)
+	case "-key":
+		c.inst(t, 0xE09E|x<<8)
+	default:
+		// The comparisons subtract in a scratch register, vf unless
+		// compare-temp is aliased, and test the flag: vf is set when
+		// a >= b.
+		a, b, aReg, bReg := x, y, true, cond.isReg
+		if op == ">" || op == "<=" {
+			a, b, aReg, bReg = y, x, cond.isReg, true
+		}
+
+		temp := uint16(0xF)
+		if r, ok := c.aliases["compare-temp"]; ok {
+			temp = uint16(r)
+		}
+
+		switch {
+		case bReg && aReg:
+			c.inst(t, 0x8000|temp<<8|a<<4)
+			c.inst(t, 0x8005|temp<<8|b<<4)
+		case bReg:
+			c.inst(t, 0x6000|temp<<8|a)
+			c.inst(t, 0x8005|temp<<8|b<<4)
+		default:
+			// temp = a - b, where only a is a register.
+			c.inst(t, 0x6000|temp<<8|b)
+			c.inst(t, 0x8007|temp<<8|a<<4)
+		}
+
+		// Skip when the condition is false: for < and > when a >= b.
+		if op == "<" || op == ">" {
+			c.inst(t, 0x3F01)
+		} else {
+			c.inst(t, 0x3F00)
+		}
+	}
+}
+
+// conditional compiles if ... then and if ... begin.
+func (c *compiler) conditional(t token) {
+	cond := c.condition(t)
+
+	switch next := c.next(); next.text {
+	case "then":
+		cond.compile(c, false)
+	case "begin":
+		cond.compile(c, true)
+		c.branches = append(c.branches, branch{tok: t, addr: c.here})
+		c.inst(t, 0x1000)
+	default:
+		c.fail(next, "expected then or begin, found "+next.text)
+	}
+}
+
+// expand replaces a macro call with the body of the macro.
+func (c *compiler) expand(t token, m *macro) {
+	args := make(map[string]token, len(m.params))
+	for _, p := range m.params {
+		args[p] = c.next()
+	}
+
+	c.expansions++
+	if c.expansions > maxExpansions {
+		c.fail(t, "too many macro expansions")
+	}
+
+	m.calls++
+	body := make([]token, len(m.body))
+	for i, b := range m.body {
+		if a, ok := args[b.text]; ok && !b.quoted {
+			b = a
+		} else if b.text == "CALLS" && !b.quoted {
+			b.text = strconv.Itoa(m.calls - 1)
+		}
+		body[i] = b
+	}
+	c.tokens = slices.Insert(c.tokens, c.pos, body...)
+}
+
+// directive compiles the statements that start with a colon, and reports
+// whether t was one.
+func (c *compiler) directive(t token) bool {
+	switch t.text {
+	case ":":
+		c.define(c.next(), float64(c.here), true)
+	case ":alias":
+		name := c.next()
+		x, _ := c.register()
+		if _, ok := number(name.text); ok || isKeyword(name.text) || name.quoted {
+			c.fail(name, "bad alias "+name.text)
+		}
+		c.aliases[name.text] = uint8(x)
+	case ":const":
+		name := c.next()
+		v, _ := c.value()
+		c.define(name, v, false)
+	case ":calc":
+		name := c.next()
+		c.define(name, c.calc(), false)
+	case ":byte":
+		c.emit(t, c.byteValue())
+	case ":pointer":
+		target := c.next()
+		addr := c.here
+		c.emit(t, 0, 0)
+		c.reference(target, addr, fixupLong)
+	case ":org":
+		v, at := c.value()
+		if v < float64(chip8.ProgramStartAddress) || v >= float64(len(c.rom)) {
+			c.fail(at, "address "+at.text+" is outside the program")
+		}
+		c.here = int(v)
+	case ":unpack":
+		nibble := c.nibbleValue()
+		target := c.next()
+		addr := c.here
+		c.inst(t, 0x6000|nibble<<4)
+		c.inst(t, 0x6100)
+		c.reference(target, addr, fixupUnpack)
+	case ":next":
+		c.define(c.next(), float64(c.here+1), true)
+	case ":call":
+		c.call(t, c.next())
+	case ":macro":
+		c.macro()
+	case ":breakpoint":
+		c.next()
+	case ":monitor":
+		c.next()
+		c.next()
+	case ":assert":
+		msg := "assertion failed"
+		if c.peek().quoted {
+			msg += ": " + c.next().text
+		}
+		if c.calc() == 0 {
+			c.fail(t, msg)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// macro reads the definition of a macro: its name, its parameters and its
+// body in braces.
+func (c *compiler) macro() {
+	name := c.next()
+	if _, ok := c.macros[name.text]; ok {
+		c.fail(name, name.text+" is already defined")
+	}
+
+	m := &macro{}
+	for {
+		p := c.next()
+		if p.text == "{" && !p.quoted {
+			break
+		}
+		m.params = append(m.params, p.text)
+	}
+
+	for depth := 1; ; {
+		b := c.next()
+		if !b.quoted {
+			switch b.text {
+			case "{":
+				depth++
+			case "}":
+				depth--
+			}
+		}
+		if depth == 0 {
+			break
+		}
+		m.body = append(m.body, b)
+	}
+	c.macros[name.text] = m
+}
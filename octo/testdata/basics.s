@@ -0,0 +1,32 @@
+	JP loc_202              ; 200
+loc_202:
+	CLS                     ; 202
+	LD V1, 0A               ; 204
+	LD V2, 03               ; 206
+	LD I, dat_23A           ; 208
+	DRW V1, V2, 04          ; 20A
+	ADD V1, V2              ; 20C
+	SUB V1, V2              ; 20E
+	SUBN V1, V2             ; 210
+	OR V1, V2               ; 212
+	AND V1, V2              ; 214
+	XOR V1, V2              ; 216
+	SHR V1, V2              ; 218
+	SHL V1, V2              ; 21A
+	RND V0, 3F              ; 21C
+	LD V3, DT               ; 21E
+	LD DT, V3               ; 220
+	LD ST, V3               ; 222
+	ADD I, V0               ; 224
+	LD F, V0                ; 226
+	LD B, V0                ; 228
+	LD [I], V3              ; 22A
+	LD V3, [I]              ; 22C
+	LD V4, K                ; 22E
+	SKNP V4                 ; 230
+	ADD V1, 01              ; 232
+	SKP V4                  ; 234
+	ADD V1, FF              ; 236
+	JP loc_202              ; 238
+dat_23A:
+	DB F0, 90, 90, F0
@@ -0,0 +1,34 @@
+	JP loc_202              ; 200
+loc_202:
+	LD V0, 00               ; 202
+loc_204:
+	ADD V0, 01              ; 204
+	SNE V0, 05              ; 206
+	LD V1, 01               ; 208
+	SNE V0, 0A              ; 20A
+	JP loc_212              ; 20C
+	CALL sub_22C            ; 20E
+	JP loc_204              ; 210
+loc_212:
+	LD VF, V1               ; 212
+	SUB VF, V0              ; 214
+	SE VF, 00               ; 216
+	JP loc_21E              ; 218
+	LD V2, 01               ; 21A
+	JP loc_220              ; 21C
+loc_21E:
+	LD V2, 02               ; 21E
+loc_220:
+	LD VF, 03               ; 220
+	SUB VF, V0              ; 222
+	SE VF, 01               ; 224
+	JP loc_22A              ; 226
+	LD V3, 03               ; 228
+loc_22A:
+	JP V0, loc_230          ; 22A
+sub_22C:
+	ADD V5, 02              ; 22C
+	RET                     ; 22E
+loc_230:
+	JP loc_202              ; 230
+	DB 12, 2C
@@ -0,0 +1,22 @@
+	JP loc_202              ; 200
+loc_202:
+	HIGH                    ; 202
+	LOW                     ; 204
+	SCD 4                   ; 206
+	SCU 2                   ; 208
+	SCL                     ; 20A
+	SCR                     ; 20C
+	LD HF, V0               ; 20E
+	LD R, V3                ; 210
+	LD V3, R                ; 212
+	PLANE 3                 ; 214
+	LD I, LONG dat_226      ; 216
+	AUDIO                   ; 21A
+	PITCH V1                ; 21C
+	LD [I], V2-V5           ; 21E
+	LD V5-V2, [I]           ; 220
+	DRW V0, V1, 00          ; 222
+	EXIT                    ; 224
+dat_226:
+	DB 00, FF, 00, FF, 00, FF, 00, FF
+	DB 00, FF, 00, FF, 00, FF, 00, FF
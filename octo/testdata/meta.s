@@ -0,0 +1,18 @@
+	JP loc_202              ; 200
+loc_202:
+	ADD V1, V1              ; 202
+	ADD V1, V1              ; 204
+	LD V2, 20               ; 206
+	LD V0, A2               ; 208
+	LD V1, 14               ; 20A
+	LD I, dat_214           ; 20C
+	LD V3, 00               ; 20E
+	LD I, 20F               ; 210
+	JP loc_202              ; 212
+dat_214:
+	DB 21, 02, 02, FF, 00, 00, 00, 00
+	DB 00, 00, 00, 00, 00, 00, 00, 00
+	DB 00, 00, 00, 00, 00, 00, 00, 00
+	DB 00, 00, 00, 00, 00, 00, 00, 00
+	DB 00, 00, 00, 00, 00, 00, 00, 00
+	DB 00, 00, 00, 00, 00, EE
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package octo
+
+import (
+	"strconv"
+	"strings"
+)
+
+// token is a word of source, or a quoted string.
+type token struct {
+	text   string
+	line   int
+	col    int
+	quoted bool
+}
+
+// tokenize splits source into words separated by white space. A # starts a
+// comment that runs to the end of the line.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+
+	for i, text := range strings.Split(src, "\n") {
+		line := i + 1
+
+		for col := 0; col < len(text); {
+			c := text[col]
+			switch {
+			case c == '#':
+				col = len(text)
+			case c == ' ' || c == '\t' || c == '\r':
+				col++
+			case c == '"':
+				end := strings.IndexByte(text[col+1:], '"')
+				if end < 0 {
+					return nil, &Error{Line: line, Col: col + 1, Msg: "missing closing quote"}
+				}
+				tokens = append(tokens, token{text: text[col+1 : col+1+end], line: line, col: col + 1, quoted: true})
+				col += end + 2
+			default:
+				end := strings.IndexAny(text[col:], " \t\r")
+				if end < 0 {
+					end = len(text) - col
+				}
+				tokens = append(tokens, token{text: text[col : col+end], line: line, col: col + 1})
+				col += end
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// number reads a decimal, 0x hexadecimal or 0b binary number, which may be
+// negative.
+func number(s string) (int, bool) {
+	negative := false
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		s, negative = rest, true
+	}
+
+	base := 10
+	switch {
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0X"):
+		s, base = s[2:], 16
+	case strings.HasPrefix(s, "0b"), strings.HasPrefix(s, "0B"):
+		s, base = s[2:], 2
+	}
+
+	n, err := strconv.ParseUint(s, base, 32)
+	if err != nil || s == "" {
+		return 0, false
+	}
+
+	if negative {
+		return -int(n), true
+	}
+	return int(n), true
+}
+
+// register reads v0 to vf.
+func register(s string) (uint8, bool) {
+	if len(s) != 2 || (s[0] != 'v' && s[0] != 'V') {
+		return 0, false
+	}
+
+	x, err := strconv.ParseUint(s[1:], 16, 4)
+	return uint8(x), err == nil
+}
@@ -0,0 +1,500 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package recompile translates ROM images into Go packages.
+//
+// The code reachable from the start of a program, as disasm.Trace finds it,
+// is split into blocks, and each block becomes a Go function. Loads,
+// arithmetic and changes to I are written out in Go for the quirks chosen,
+// and skips and jumps become branches within the function while they stay
+// in its block. Calls, returns, draws, reads of the keypad and writes to
+// memory run through the processor with chip8.Processor.ExecuteAt, and a
+// block ends at those that may change the flow or memory. A processor
+// given the blocks with chip8.WithBlocks runs them in place of its
+// instructions for as long as memory holds the bytes they were translated
+// from. Everything else, such as the targets of computed jumps and code the
+// program writes, is left to the processor.
+package recompile
+
+import (
+	"emul8/byteconv"
+	"emul8/chip8"
+	"emul8/disasm"
+	"errors"
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// bytesPerLine is the most ROM bytes written on one line.
+const bytesPerLine = 12
+
+// Options chooses the package Translate writes.
+type Options struct {
+	Package string // The package name, main by default.
+	Source  string // The name of the ROM, for comments.
+	Mode    string // A name from chip8.Modes.
+	Quirks  string // A name from chip8.QuirksPresets.
+}
+
+// instruction is an instruction of a block.
+type instruction struct {
+	addr uint16
+	op   chip8.Opcode
+	in   *chip8.Instruction
+}
+
+type translator struct {
+	rom    []byte
+	mode   chip8.Mode
+	quirks chip8.Quirks
+	code   map[uint16]int // The size of each reachable instruction.
+	labels map[uint16]string
+	b      strings.Builder
+}
+
+// Translate returns the source of a package holding a ROM loaded at
+// chip8.ProgramStartAddress, its blocks as Go functions and the options
+// that run them. The main package also runs the program in a window.
+func Translate(rom []byte, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "main"
+	}
+	mode, ok := chip8.Modes[opts.Mode]
+	if !ok {
+		return nil, errors.New("unknown mode: " + opts.Mode)
+	}
+	quirks, ok := chip8.QuirksPresets[opts.Quirks]
+	if !ok {
+		return nil, errors.New("unknown quirks preset: " + opts.Quirks)
+	}
+
+	t := &translator{rom: rom, mode: mode, quirks: quirks}
+	t.code, t.labels = disasm.Trace(rom)
+	blocks := t.blocks()
+
+	t.header(opts)
+	t.writeROM()
+	t.writeBlocks(blocks)
+	t.writeOptions(opts)
+	if opts.Package == "main" {
+		t.writeMain()
+	}
+	for _, b := range blocks {
+		t.writeFunction(b)
+	}
+
+	return format.Source([]byte(t.b.String()))
+}
+
+// decode returns the instruction at addr, which must be reachable.
+func (t *translator) decode(addr uint16) instruction {
+	op := chip8.Opcode(t.word(addr))
+	in, _ := chip8.Decode(op)
+	return instruction{addr: addr, op: op, in: in}
+}
+
+// word returns the word at addr, which must be in the ROM.
+func (t *translator) word(addr uint16) uint16 {
+	off := int(addr) - int(chip8.ProgramStartAddress)
+	return uint16(t.rom[off])<<8 | uint16(t.rom[off+1])
+}
+
+// skipTo returns where a skip at addr passes to, over the whole of a long
+// load on XO-CHIP.
+func (t *translator) skipTo(addr uint16) uint16 {
+	next := addr + 2
+	if t.mode >= chip8.ModeXOCHIP && t.code[next] == 4 && t.word(next) == 0xF000 {
+		return next + 4
+	}
+	return next + 2
+}
+
+// blocks splits the reachable code into blocks. A block starts at the start
+// of the program, at a label, after an instruction that ends a block and at
+// the instruction a skip in an earlier block passes to. It runs on past a
+// jump only when a skip within it passes over the jump.
+func (t *translator) blocks() [][]instruction {
+	starts := map[uint16]bool{chip8.ProgramStartAddress: true}
+	for addr := range t.labels {
+		starts[addr] = true
+	}
+
+	addrs := make([]uint16, 0, len(t.code))
+	for addr := range t.code {
+		addrs = append(addrs, addr)
+	}
+	slices.Sort(addrs)
+
+	var blocks [][]instruction
+	var block []instruction
+	skips := make(map[uint16]bool) // Where the skips of the block pass to.
+	for _, addr := range addrs {
+		if n := len(block); n > 0 {
+			last := block[n-1]
+			follows := last.addr+uint16(t.code[last.addr]) == addr
+			reached := last.in.Flow != chip8.FlowJump || skips[addr]
+			if !follows || !reached || starts[addr] || ends(last.in) || n == chip8.MaxBlockLength {
+				for to := range skips {
+					if to >= addr {
+						starts[to] = true
+					}
+				}
+				clear(skips)
+				blocks = append(blocks, block)
+				block = nil
+			}
+		}
+
+		c := t.decode(addr)
+		if branches(c.in) && c.in.Flow == chip8.FlowSkip {
+			skips[t.skipTo(addr)] = true
+		}
+		block = append(block, c)
+	}
+	if block != nil {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// branches reports whether an instruction is a jump or skip translated
+// into a Go branch.
+func branches(in *chip8.Instruction) bool {
+	switch in.Pattern {
+	case 0x1000, 0x3000, 0x4000, 0x5000, 0x9000:
+		return true
+	}
+	return false
+}
+
+// ends reports whether an instruction ends a block, as it ends the
+// processor's own blocks, unless it becomes a Go branch.
+func ends(in *chip8.Instruction) bool {
+	return in.EndsBlock() && !branches(in)
+}
+
+// line writes the concatenation of parts as a line.
+func (t *translator) line(parts ...string) {
+	for _, s := range parts {
+		t.b.WriteString(s)
+	}
+	t.b.WriteByte('\n')
+}
+
+func (t *translator) header(opts Options) {
+	source := ""
+	if opts.Source != "" {
+		source = " from " + opts.Source
+	}
+	t.line("// Code generated by emul8 recompile", source, ". DO NOT EDIT.")
+	t.line()
+	t.line("package ", opts.Package)
+	t.line()
+	if opts.Package == "main" {
+		t.line("import (")
+		t.line(`"emul8"`)
+		t.line(`"emul8/chip8"`)
+		t.line(`"emul8/gui"`)
+		t.line(`"log"`)
+		t.line(")")
+	} else {
+		t.line(`import "emul8/chip8"`)
+	}
+	t.line()
+}
+
+func (t *translator) writeROM() {
+	t.line("// ROM is the program the package was translated from.")
+	t.line("var ROM = []byte{")
+	for off := 0; off < len(t.rom); off += bytesPerLine {
+		line := t.rom[off:min(off+bytesPerLine, len(t.rom))]
+		hex := make([]string, len(line))
+		for i, v := range line {
+			hex[i] = "0x" + byteconv.Btoh([]byte{v}, 2)
+		}
+		t.line(strings.Join(hex, ", "), ",")
+	}
+	t.line("}")
+	t.line()
+}
+
+func (t *translator) writeBlocks(blocks [][]instruction) {
+	t.line("// Blocks are the runs of instructions reachable from the start of ROM,")
+	t.line("// each translated into one of the functions below.")
+	t.line("var Blocks = []chip8.Block{")
+	for _, b := range blocks {
+		start := int(b[0].addr) - int(chip8.ProgramStartAddress)
+		last := b[len(b)-1]
+		end := int(last.addr) + t.code[last.addr] - int(chip8.ProgramStartAddress)
+		t.line("{Start: ", "0x"+chip8.FormatAddress(b[0].addr), ", Code: ROM[", strconv.Itoa(start), ":", strconv.Itoa(end), "], Run: ", t.name(b[0].addr), "},")
+	}
+	t.line("}")
+	t.line()
+}
+
+func (t *translator) writeOptions(opts Options) {
+	t.line("// Options returns the options that run ROM in ", opts.Mode, " mode with the")
+	t.line("// ", opts.Quirks, " quirks, along with its blocks.")
+	t.line("func Options() []chip8.Option {")
+	t.line("return []chip8.Option{")
+	t.line(`chip8.WithMode(chip8.Modes["`, opts.Mode, `"]),`)
+	t.line(`chip8.WithQuirks(chip8.QuirksPresets["`, opts.Quirks, `"]),`)
+	t.line("chip8.WithBlocks(Blocks),")
+	t.line("}")
+	t.line("}")
+	t.line()
+}
+
+func (t *translator) writeMain() {
+	t.line("func main() {")
+	t.line("// The machine runs a frame at a time with chip8.Processor.RunFrame,")
+	t.line("// and so runs the blocks, unless the debugger is stepping.")
+	t.line("m := emul8.NewMachine(Options()...)")
+	t.line("if err := m.Load(ROM); err != nil {")
+	t.line("log.Fatal(err)")
+	t.line("}")
+	t.line("gui.Run(m)")
+	t.line("}")
+	t.line()
+}
+
+// function is a block being written as a Go function.
+type function struct {
+	block   map[uint16]bool // The addresses of its instructions.
+	targets map[uint16]bool // Those branched to, which are labeled.
+	regs    bool            // Whether it uses the registers.
+	flag    bool            // Whether it needs a variable for VF.
+	body    strings.Builder
+}
+
+func (f *function) line(parts ...string) {
+	for _, s := range parts {
+		f.body.WriteString(s)
+	}
+	f.body.WriteByte('\n')
+}
+
+// exit branches to the instruction at addr, leaving the function when it
+// is outside the block.
+func (f *function) exit(addr uint16) {
+	if f.block[addr] {
+		f.line("goto ", goLabel(addr))
+		return
+	}
+	f.line("p.SetProgramCounter(0x", chip8.FormatAddress(addr), ")")
+	f.line("return ran, nil")
+}
+
+// writeFunction writes a block as a function that runs up to n of its
+// instructions, each commented with its source, and returns how many ran.
+func (t *translator) writeFunction(b []instruction) {
+	f := &function{block: make(map[uint16]bool), targets: make(map[uint16]bool)}
+	for _, c := range b {
+		f.block[c.addr] = true
+	}
+	for _, c := range b {
+		if to, ok := t.branch(f, c); ok && f.block[to] {
+			f.targets[to] = true
+		}
+	}
+
+	for _, c := range b {
+		t.translate(f, c)
+	}
+	last := b[len(b)-1]
+	switch {
+	case !t.inline(f, last):
+		f.line("return ran, nil")
+	case last.in.Pattern != 0x1000:
+		f.exit(last.addr + uint16(t.code[last.addr]))
+	}
+
+	t.line("func ", t.name(b[0].addr), "(p *chip8.Processor, n int) (int, error) {")
+	if f.regs {
+		t.line("v := p.V()")
+	}
+	if f.flag {
+		t.line("var flag byte")
+	}
+	t.line("ran := 0")
+	t.b.WriteString(f.body.String())
+	t.line("}")
+	t.line()
+}
+
+// branch returns where a jump or skip translated into a Go branch goes.
+func (t *translator) branch(f *function, c instruction) (uint16, bool) {
+	if !t.inline(f, c) {
+		return 0, false
+	}
+	switch c.in.Pattern {
+	case 0x1000:
+		return uint16(c.op) & 0x0FFF, true
+	case 0x3000, 0x4000, 0x5000, 0x9000:
+		return t.skipTo(c.addr), true
+	}
+	return 0, false
+}
+
+// inline reports whether an instruction is translated into Go, rather than
+// run with ExecuteAt. On XO-CHIP, a skip is only translated when the
+// instruction it passes over is in the block, as its size depends on it.
+func (t *translator) inline(f *function, c instruction) bool {
+	if c.in.Mode > t.mode {
+		return false
+	}
+	switch c.in.Pattern {
+	case 0x3000, 0x4000, 0x5000, 0x9000:
+		return t.mode < chip8.ModeXOCHIP || f.block[c.addr+2]
+	case 0x1000, 0x6000, 0x7000, 0x8000, 0x8001, 0x8002, 0x8003, 0x8004, 0x8005, 0x8006, 0x8007, 0x800E, 0xA000, 0xF000, 0xF01E:
+		return true
+	}
+	return false
+}
+
+// translate writes an instruction, which first leaves the function when n
+// instructions have run.
+func (t *translator) translate(f *function, c instruction) {
+	if f.targets[c.addr] {
+		f.line(goLabel(c.addr), ":")
+	}
+	f.line("// ", t.source(c))
+	f.line("if ran == n {")
+	f.line("p.SetProgramCounter(0x", chip8.FormatAddress(c.addr), ")")
+	f.line("return ran, nil")
+	f.line("}")
+	f.line("ran++")
+
+	if !t.inline(f, c) {
+		f.line("if ok, err := p.ExecuteAt(0x", chip8.FormatAddress(c.addr), ", 0x", byteconv.Btoh(byteconv.U16tob(uint16(c.op)), 4), "); !ok {")
+		f.line("return ran - 1, err")
+		f.line("}")
+		return
+	}
+
+	x, y := reg(uint16(c.op)>>8), reg(uint16(c.op)>>4)
+	nn := "0x" + byteconv.Btoh([]byte{byte(c.op)}, 2)
+	vf := reg(0xF)
+	switch pattern := c.in.Pattern; {
+	case pattern == 0x8004 || pattern == 0x8005 || pattern == 0x8006 || pattern == 0x8007 || pattern == 0x800E:
+		f.regs, f.flag = true, true
+	case pattern != 0x1000 && pattern != 0xA000 && pattern != 0xF000 && !(pattern == 0x8000 && x == y):
+		f.regs = true
+	}
+
+	switch c.in.Pattern {
+	case 0x1000:
+		f.exit(uint16(c.op) & 0x0FFF)
+	case 0x3000, 0x4000, 0x5000, 0x9000:
+		cond := map[chip8.Opcode]string{0x3000: x + " == " + nn, 0x4000: x + " != " + nn, 0x5000: x + " == " + y, 0x9000: x + " != " + y}
+		f.line("if ", cond[c.in.Pattern], " {")
+		f.exit(t.skipTo(c.addr))
+		f.line("}")
+	case 0x6000:
+		f.line(x, " = ", nn)
+	case 0x7000:
+		f.line(x, " += ", nn)
+	case 0x8000:
+		if x != y {
+			f.line(x, " = ", y)
+		}
+	case 0x8001, 0x8002, 0x8003:
+		op := map[chip8.Opcode]string{0x8001: " |= ", 0x8002: " &= ", 0x8003: " ^= "}
+		f.line(x, op[c.in.Pattern], y)
+		if t.quirks.VFReset {
+			f.line(vf, " = 0")
+		}
+	case 0x8004:
+		// The flag is written after the result, as the interpreter does, so
+		// that it is kept when VF is the destination.
+		f.line("flag = 0")
+		f.line("if uint16(", x, ")+uint16(", y, ") > 0xFF {")
+		f.line("flag = 1")
+		f.line("}")
+		f.line(x, " += ", y)
+		f.line(vf, " = flag")
+	case 0x8005:
+		f.line("flag = 0")
+		f.line("if ", x, " >= ", y, " {")
+		f.line("flag = 1")
+		f.line("}")
+		f.line(x, " -= ", y)
+		f.line(vf, " = flag")
+	case 0x8007:
+		f.line("flag = 0")
+		f.line("if ", y, " >= ", x, " {")
+		f.line("flag = 1")
+		f.line("}")
+		f.line(x, " = ", y, " - ", x)
+		f.line(vf, " = flag")
+	case 0x8006, 0x800E:
+		if t.quirks.ShiftUsesVY && x != y {
+			f.line(x, " = ", y)
+		}
+		if c.in.Pattern == 0x8006 {
+			f.line("flag = ", x, " & 1")
+			f.line(x, " >>= 1")
+		} else {
+			f.line("flag = ", x, " >> 7")
+			f.line(x, " <<= 1")
+		}
+		f.line(vf, " = flag")
+	case 0xA000:
+		f.line("p.SetIndex(0x", chip8.FormatAddress(uint16(c.op)&0x0FFF), ")")
+	case 0xF000:
+		f.line("p.SetIndex(0x", byteconv.Btoh(byteconv.U16tob(t.word(c.addr+2)), 4), ")")
+	case 0xF01E:
+		f.line("p.SetIndex(p.Index() + uint16(", x, "))")
+	}
+}
+
+// reg returns the register in the low four bits of r as Go.
+func reg(r uint16) string {
+	return "v[0x" + byteconv.Btoh([]byte{byte(r & 0xF)}, 1) + "]"
+}
+
+// goLabel returns the Go label of the instruction at addr.
+func goLabel(addr uint16) string {
+	return "at_" + chip8.FormatAddress(addr)
+}
+
+// name returns the name of the function of the block at addr, which is its
+// label where it has one.
+func (t *translator) name(addr uint16) string {
+	if label, ok := t.labels[addr]; ok {
+		return label
+	}
+	return "block_" + chip8.FormatAddress(addr)
+}
+
+// source returns an instruction as package asm writes it, with labels for
+// its addresses.
+func (t *translator) source(c instruction) string {
+	label := func(addr uint16) string {
+		if label, ok := t.labels[addr]; ok {
+			return label
+		}
+		return chip8.FormatAddress(addr)
+	}
+
+	str := c.op.Format(label)
+	if c.in.Size == 4 {
+		str += " " + label(t.word(c.addr+2))
+	}
+	return str
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package recompile_test
+
+import (
+	"bytes"
+	"emul8/asm"
+	"emul8/chip8"
+	"emul8/recompile"
+	"emul8/recompile/internal/long"
+	"emul8/recompile/internal/loops"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the packages in internal from testdata")
+
+// programs are the packages in internal, each translated from the program
+// of the same name in testdata.
+var programs = []struct {
+	name, mode, quirks string
+	rom                []byte
+	options            func() []chip8.Option
+}{
+	{"loops", "chip8", "vip", loops.ROM, loops.Options},
+	{"long", "xochip", "xochip", long.ROM, long.Options},
+}
+
+// TestGolden translates each program in testdata and compares the source
+// with its package in internal.
+func TestGolden(t *testing.T) {
+	for _, prog := range programs {
+		t.Run(prog.name, func(t *testing.T) {
+			src, err := os.ReadFile(filepath.Join("testdata", prog.name+".s"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rom, err := asm.Assemble(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := recompile.Translate(rom, recompile.Options{Package: prog.name, Source: prog.name + ".ch8", Mode: prog.mode, Quirks: prog.quirks})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("internal", prog.name, prog.name+".go")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Translate() differs from %s; run go test -update to rewrite it", golden)
+			}
+		})
+	}
+}
+
+// TestBlocks runs each translated program beside the interpreter, with keys
+// pressed in turn, and compares the two processors after every frame.
+func TestBlocks(t *testing.T) {
+	for _, prog := range programs {
+		t.Run(prog.name, func(t *testing.T) {
+			interpreter := chip8.NewProcessor(chip8.WithMode(chip8.Modes[prog.mode]), chip8.WithQuirks(chip8.QuirksPresets[prog.quirks]), chip8.WithSeed(1))
+			translated := chip8.NewProcessor(slices.Concat(prog.options(), []chip8.Option{chip8.WithSeed(1)})...)
+			for _, p := range []*chip8.Processor{interpreter, translated} {
+				if err := p.Load(prog.rom); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for frame := range 300 {
+				key := uint8(frame / 10 % chip8.KeyCount)
+				for _, p := range []*chip8.Processor{interpreter, translated} {
+					p.SetKey(key, frame%10 < 5)
+				}
+
+				// Frames of different lengths leave blocks part way through.
+				n := 1 + frame%50
+				if _, err := interpreter.RunFrame(n); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := translated.RunFrame(n); err != nil {
+					t.Fatal(err)
+				}
+
+				want, _ := interpreter.MarshalBinary()
+				got, _ := translated.MarshalBinary()
+				if !bytes.Equal(got, want) {
+					t.Fatalf("frame %d: state differs from the interpreter's", frame)
+				}
+			}
+		})
+	}
+}
+
+// TestLockstep checks every block against the interpreter as it runs.
+func TestLockstep(t *testing.T) {
+	for _, prog := range programs {
+		t.Run(prog.name, func(t *testing.T) {
+			p := chip8.NewProcessor(slices.Concat(prog.options(), []chip8.Option{chip8.WithSeed(1), chip8.WithLockstep()})...)
+			if err := p.Load(prog.rom); err != nil {
+				t.Fatal(err)
+			}
+			for frame := range 300 {
+				p.SetKey(uint8(frame%chip8.KeyCount), frame%2 == 0)
+				if _, err := p.RunFrame(chip8.DefaultCyclesPerFrame); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	for _, opts := range []recompile.Options{{Mode: "chip9", Quirks: "vip"}, {Mode: "chip8", Quirks: "cosmac"}} {
+		if _, err := recompile.Translate([]byte{0x12, 0x00}, opts); err == nil {
+			t.Errorf("Translate() with %+v succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+; Skips over XO-CHIP long loads, within a block and at the end of one.
+start:  LD V0, 00
+loop:   ADD V0, 01
+        SE V0, 80
+        LD I, LONG data
+        SNE V0, 40
+        LD I, LONG start
+        LD V1, [I]
+        SE V0, 00
+        JP loop
+        ADD V2, 01
+        SE V2, 03
+        JP start
+        SE V1, 00
+next:   LD I, LONG data
+        ADD V3, 01
+        SNE V3, 00
+        JP next
+        JP start
+data:   DB 01, 02
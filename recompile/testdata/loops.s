@@ -0,0 +1,36 @@
+; Arithmetic in loops of skips and jumps, with calls, a draw that waits for
+; the vertical blank and a key that restarts the program.
+start:  CLS
+        LD V0, 00
+        LD V1, 01
+loop:   ADD V0, V1
+        LD V2, V0
+        SHR V2
+        XOR V3, V2
+        ADD V4, 03
+        SUB V5, V4
+        SUBN V6, V5
+        SHL V7, V6
+        OR V6, V5
+        AND V6, V3
+        ADD V8, V0
+        LD VF, V8
+        ADD VF, V1
+        SE V0, V4
+        SNE V1, 02
+        LD V9, V9
+        SE V2, 10
+        JP loop
+        LD I, sprite
+        ADD I, V1
+        DRW V0, V1, 5
+        RND VA, 0F
+        CALL count
+        SKNP VA
+        JP start
+        JP loop
+count:  ADD VB, 01
+        SNE VB, 00
+        ADD VC, 01
+        RET
+sprite: DB F0, 90, F0, 90, F0
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rewind
+
+import "encoding/binary"
+
+// Buffer keeps a bounded history of snapshots. Only the newest snapshot is
+// held in full. Each older snapshot is stored as the difference from the
+// one that followed it, so that walking backwards never needs more than
+// the newest snapshot and one delta. Once the buffer is full, pushing a
+// snapshot discards the oldest.
+type Buffer struct {
+	head     []byte
+	deltas   [][]byte // ring of deltas, oldest at start
+	start    int
+	count    int
+	capacity int
+}
+
+// New returns a buffer that holds up to capacity snapshots.
+func New(capacity int) *Buffer {
+	return &Buffer{
+		deltas:   make([][]byte, max(capacity-1, 0)),
+		capacity: capacity,
+	}
+}
+
+// Len returns the number of snapshots held.
+func (b *Buffer) Len() int {
+	if b.head == nil {
+		return 0
+	}
+	return b.count + 1
+}
+
+// Size returns the number of bytes used to hold the snapshots.
+func (b *Buffer) Size() int {
+	size := len(b.head)
+	for i := range b.count {
+		size += len(b.deltas[(b.start+i)%len(b.deltas)])
+	}
+	return size
+}
+
+func (b *Buffer) Reset() {
+	b.head = nil
+	clear(b.deltas)
+	b.start = 0
+	b.count = 0
+}
+
+// Push records a snapshot as the newest in the buffer. The buffer keeps its
+// own copy of the snapshot.
+func (b *Buffer) Push(snapshot []byte) {
+	if b.capacity == 0 {
+		return
+	}
+
+	if b.head != nil && len(b.deltas) > 0 {
+		if b.count == len(b.deltas) {
+			// Drop the oldest delta to make room.
+			b.deltas[b.start] = nil
+			b.start = (b.start + 1) % len(b.deltas)
+			b.count--
+		}
+		b.deltas[(b.start+b.count)%len(b.deltas)] = diff(b.head, snapshot)
+		b.count++
+	}
+	b.head = append(b.head[:0:0], snapshot...)
+}
+
+// Pop removes and returns the newest snapshot.
+func (b *Buffer) Pop() ([]byte, bool) {
+	if b.head == nil {
+		return nil, false
+	}
+
+	snapshot := b.head
+	b.head = nil
+
+	if b.count > 0 {
+		last := (b.start + b.count - 1) % len(b.deltas)
+		b.head = patch(snapshot, b.deltas[last])
+		b.deltas[last] = nil
+		b.count--
+	}
+	return snapshot, true
+}
+
+// A delta is either a full copy of the older snapshot, when the two differ
+// in length, or the run-length encoded XOR of the two. The XOR is written
+// as alternating runs: a count of unchanged bytes, followed by a count of
+// changed bytes and the XOR of each.
+const (
+	deltaFull byte = iota
+	deltaXOR
+)
+
+// diff encodes older as a delta from newer.
+func diff(older, newer []byte) []byte {
+	if len(older) != len(newer) {
+		return append([]byte{deltaFull}, older...)
+	}
+
+	d := []byte{deltaXOR}
+	for i := 0; i < len(older); {
+		start := i
+		for i < len(older) && older[i] == newer[i] {
+			i++
+		}
+		d = binary.AppendUvarint(d, uint64(i-start))
+
+		start = i
+		for i < len(older) && older[i] != newer[i] {
+			i++
+		}
+		d = binary.AppendUvarint(d, uint64(i-start))
+		for j := start; j < i; j++ {
+			d = append(d, older[j]^newer[j])
+		}
+	}
+	return d
+}
+
+// patch reverses diff, recovering the older snapshot from the newer one.
+func patch(newer, d []byte) []byte {
+	if d[0] == deltaFull {
+		return append([]byte{}, d[1:]...)
+	}
+
+	older := append([]byte{}, newer...)
+	d = d[1:]
+	for i := 0; len(d) > 0; {
+		same, n := binary.Uvarint(d)
+		d = d[n:]
+		i += int(same)
+
+		changed, n := binary.Uvarint(d)
+		d = d[n:]
+		for j := range int(changed) {
+			older[i+j] ^= d[j]
+		}
+		d = d[changed:]
+		i += int(changed)
+	}
+	return older
+}
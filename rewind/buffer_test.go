@@ -0,0 +1,189 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rewind
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+)
+
+// snapshots returns n snapshots of size bytes, each changing a few bytes
+// of the one before, as the frames of a program do.
+func snapshots(n, size int) [][]byte {
+	r := rand.New(rand.NewPCG(1, 2))
+	s := make([]byte, size)
+	var all [][]byte
+	for range n {
+		for range 1 + r.IntN(4) {
+			s[r.IntN(size)] = byte(r.Uint32())
+		}
+		all = append(all, bytes.Clone(s))
+	}
+	return all
+}
+
+func TestPushPop(t *testing.T) {
+	want := snapshots(20, 64)
+	b := New(len(want))
+	for _, s := range want {
+		b.Push(s)
+	}
+	if b.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", b.Len(), len(want))
+	}
+
+	for i := len(want) - 1; i >= 0; i-- {
+		got, ok := b.Pop()
+		if !ok {
+			t.Fatalf("Pop() of snapshot %d failed", i)
+		}
+		if !bytes.Equal(got, want[i]) {
+			t.Fatalf("Pop() = % X, want snapshot %d, % X", got, i, want[i])
+		}
+	}
+	if b.Len() != 0 {
+		t.Errorf("Len() = %d after popping everything", b.Len())
+	}
+}
+
+func TestPushCopies(t *testing.T) {
+	b := New(2)
+	s := []byte{1, 2, 3}
+	b.Push(s)
+	s[0] = 9
+
+	if got, _ := b.Pop(); !bytes.Equal(got, []byte{1, 2, 3}) {
+		t.Errorf("Pop() = % X, changed with the caller's slice", got)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	all := snapshots(10, 32)
+	b := New(4)
+	for _, s := range all {
+		b.Push(s)
+	}
+	if b.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4", b.Len())
+	}
+
+	// Only the newest four are kept, and the oldest of them is still whole.
+	for i := len(all) - 1; i >= len(all)-4; i-- {
+		got, ok := b.Pop()
+		if !ok || !bytes.Equal(got, all[i]) {
+			t.Fatalf("Pop() = % X, %v, want snapshot %d", got, ok, i)
+		}
+	}
+	if got, ok := b.Pop(); ok {
+		t.Errorf("Pop() = % X past the capacity", got)
+	}
+}
+
+func TestLengthChange(t *testing.T) {
+	// A program switching modes changes the size of its snapshots.
+	want := [][]byte{{1, 2, 3, 4}, {1, 2, 3, 4, 5, 6}, {6, 5}, {6, 4}}
+	b := New(len(want))
+	for _, s := range want {
+		b.Push(s)
+	}
+
+	deltas := [][]byte{b.deltas[0], b.deltas[1], b.deltas[2]}
+	for i, kind := range []byte{deltaFull, deltaFull, deltaXOR} {
+		if deltas[i][0] != kind {
+			t.Errorf("delta %d is of kind %d, want %d", i, deltas[i][0], kind)
+		}
+	}
+
+	for i := len(want) - 1; i >= 0; i-- {
+		if got, _ := b.Pop(); !bytes.Equal(got, want[i]) {
+			t.Fatalf("Pop() = % X, want % X", got, want[i])
+		}
+	}
+}
+
+func TestSmallCapacity(t *testing.T) {
+	b := New(0)
+	b.Push([]byte{1})
+	if b.Len() != 0 || b.Size() != 0 {
+		t.Errorf("New(0) holds %d snapshots in %d bytes", b.Len(), b.Size())
+	}
+	if _, ok := b.Pop(); ok {
+		t.Error("Pop() of New(0) succeeded")
+	}
+
+	b = New(1)
+	b.Push([]byte{1})
+	b.Push([]byte{2})
+	if b.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", b.Len())
+	}
+	if got, ok := b.Pop(); !ok || !bytes.Equal(got, []byte{2}) {
+		t.Errorf("Pop() = % X, %v, want 02", got, ok)
+	}
+	if _, ok := b.Pop(); ok {
+		t.Error("Pop() kept an older snapshot")
+	}
+}
+
+func TestPopEmpty(t *testing.T) {
+	b := New(3)
+	if got, ok := b.Pop(); ok || got != nil {
+		t.Errorf("Pop() = % X, %v, want nothing", got, ok)
+	}
+
+	b.Push([]byte{1})
+	b.Pop()
+	if _, ok := b.Pop(); ok {
+		t.Error("Pop() succeeded after the buffer was emptied")
+	}
+
+	b.Push([]byte{1})
+	b.Push([]byte{2})
+	b.Reset()
+	if _, ok := b.Pop(); ok {
+		t.Error("Pop() succeeded after Reset")
+	}
+}
+
+func TestSize(t *testing.T) {
+	b := New(3)
+	if b.Size() != 0 {
+		t.Errorf("Size() = %d when empty", b.Size())
+	}
+
+	first := make([]byte, 1000)
+	second := bytes.Clone(first)
+	second[500] = 1
+	b.Push(first)
+	if b.Size() != 1000 {
+		t.Errorf("Size() = %d, want 1000", b.Size())
+	}
+
+	// The older snapshot costs only its delta: a kind, a run of 500 equal
+	// bytes, a run of one changed byte with its XOR, then the run of the
+	// rest and an empty run of changes.
+	b.Push(second)
+	if want := 1000 + 1 + 2 + 1 + 1 + 2 + 1; b.Size() != want {
+		t.Errorf("Size() = %d, want %d", b.Size(), want)
+	}
+
+	b.Pop()
+	if b.Size() != 1000 {
+		t.Errorf("Size() = %d after Pop, want 1000", b.Size())
+	}
+}
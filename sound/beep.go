@@ -14,10 +14,13 @@
  * limitations under the License.
  */
 
-package emul8
+// Package sound plays the machine's sound through PortAudio.
+package sound
 
 import (
 	"context"
+	"math"
+	"sync"
 	"sync/atomic"
 
 	"github.com/go-audio/audio"
@@ -29,15 +32,37 @@ import (
 const (
 	bufferSize int     = 512
 	note       float64 = 440.0
+	sampleRate int     = 44100
 )
 
 var (
 	format = audio.FormatMono44100
 )
 
+// Beep plays a sine tone, or an XO-CHIP audio pattern, from Start until
+// Stop. It is the emul8.Audio of the windowed frontend.
 type Beep struct {
 	g       errgroup.Group
 	beeping atomic.Bool
+
+	mu      sync.Mutex
+	pattern *[16]byte
+	pitch   uint8
+}
+
+// SetPattern replaces the sine tone with an XO-CHIP audio pattern, played
+// back at the given pitch.
+func (b *Beep) SetPattern(pattern [16]byte, pitch uint8) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pattern = &pattern
+	b.pitch = pitch
+}
+
+func (b *Beep) currentPattern() (*[16]byte, uint8) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pattern, b.pitch
 }
 
 func (b *Beep) Start(ctx context.Context) error {
@@ -66,7 +91,7 @@ func (b *Beep) Start(ctx context.Context) error {
 
 		out := make([]float32, bufferSize)
 
-		stream, err := portaudio.OpenDefaultStream(0, 1, 44100, len(out), &out)
+		stream, err := portaudio.OpenDefaultStream(0, 1, float64(sampleRate), len(out), &out)
 		if err != nil {
 			return err
 		}
@@ -81,13 +106,19 @@ func (b *Beep) Start(ctx context.Context) error {
 			_ = stream.Stop()
 		}()
 
+		var phase float64
+
 		for b.beeping.Load() && ctx.Err() == nil {
-			if err := osc.Fill(buffer); err != nil {
-				return err
+			if pattern, pitch := b.currentPattern(); pattern != nil {
+				phase = fillPattern(out, pattern, pitch, phase)
+			} else {
+				if err := osc.Fill(buffer); err != nil {
+					return err
+				}
+
+				f64Tof32(out, buffer.Data)
 			}
 
-			f64Tof32(out, buffer.Data)
-
 			if err := stream.Write(); err != nil {
 				return err
 			}
@@ -112,3 +143,24 @@ func f64Tof32(dst []float32, src []float64) {
 		dst[i] = float32(src[i])
 	}
 }
+
+// fillPattern plays the 128 bits of an XO-CHIP audio pattern as a square
+// wave, starting at the given bit position. It returns the position at
+// which to continue on the next call.
+func fillPattern(dst []float32, pattern *[16]byte, pitch uint8, phase float64) float64 {
+	// The playback rate is 4000 bits per second at the default pitch of 64,
+	// doubling every 48 steps.
+	rate := 4000 * math.Pow(2, (float64(pitch)-64)/48)
+	step := rate / float64(sampleRate)
+
+	for i := range dst {
+		bit := int(phase)
+		if pattern[bit/8]&(0x80>>(bit%8)) != 0 {
+			dst[i] = 1
+		} else {
+			dst[i] = -1
+		}
+		phase = math.Mod(phase+step, 128)
+	}
+	return phase
+}
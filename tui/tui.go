@@ -0,0 +1,412 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tui runs a machine in a terminal. The display is drawn with
+// half-block characters in ANSI colors, two pixels to a character, beside
+// a panel of registers and messages. It needs neither a window system nor
+// an audio library: sound rings the terminal bell.
+package tui
+
+import (
+	"context"
+	"emul8"
+	"emul8/byteconv"
+	"emul8/chip8"
+	"errors"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// keyHold is how long a keypad key stays down after the terminal reports a
+// press of it. Terminals report presses, repeated while a key is held, but
+// not releases.
+const keyHold = 200 * time.Millisecond
+
+// logLines is the number of messages shown below the registers.
+const logLines = 8
+
+// eventBuffer is the number of requests that can wait for the machine.
+const eventBuffer = 64
+
+// Control sequences of the terminal.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l\x1b[2J"
+	leaveScreen = "\x1b[0m\x1b[?25h\x1b[?1049l"
+	clearLine   = "\x1b[K"
+	resetColor  = "\x1b[0m"
+	upperHalf   = "▀"
+)
+
+// ctrlC quits, as the terminal does not turn it into a signal in raw mode.
+const ctrlC = 0x03
+
+// sequences maps the escape sequences of function keys to the requests
+// they make: F1 to F4 save a state, and F5 to F8 load one.
+var sequences = map[string]emul8.Event{
+	"\x1bOP": {Kind: emul8.Save, Slot: 0}, "\x1b[11~": {Kind: emul8.Save, Slot: 0},
+	"\x1bOQ": {Kind: emul8.Save, Slot: 1}, "\x1b[12~": {Kind: emul8.Save, Slot: 1},
+	"\x1bOR": {Kind: emul8.Save, Slot: 2}, "\x1b[13~": {Kind: emul8.Save, Slot: 2},
+	"\x1bOS": {Kind: emul8.Save, Slot: 3}, "\x1b[14~": {Kind: emul8.Save, Slot: 3},
+	"\x1b[15~": {Kind: emul8.Load, Slot: 0},
+	"\x1b[17~": {Kind: emul8.Load, Slot: 1},
+	"\x1b[18~": {Kind: emul8.Load, Slot: 2},
+	"\x1b[19~": {Kind: emul8.Load, Slot: 3},
+}
+
+// controls maps other keys to the requests they make.
+var controls = map[rune]emul8.Event{
+	'p':  {Kind: emul8.TogglePause},
+	'n':  {Kind: emul8.Step},
+	'o':  {Kind: emul8.StepOver},
+	'u':  {Kind: emul8.StepOut},
+	0x7F: {Kind: emul8.ToggleRewind}, // Backspace
+	0x08: {Kind: emul8.ToggleRewind},
+}
+
+const help = "1-4 Q-R A-F Z-V keypad  P pause  N step  O over  U out  Backspace rewind  F1-F4 save  F5-F8 load  Ctrl-C quit"
+
+// foreground and background are the color sequences of the palette.
+var foreground, background [len(emul8.Palette)]string
+
+func init() {
+	for i, c := range emul8.Palette {
+		foreground[i] = "\x1b[38;2;" + rgb(c) + "m"
+		background[i] = "\x1b[48;2;" + rgb(c) + "m"
+	}
+}
+
+func rgb(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return strconv.Itoa(int(r>>8)) + ";" + strconv.Itoa(int(g>>8)) + ";" + strconv.Itoa(int(b>>8))
+}
+
+// frontend is the terminal, as the Video, Input and Audio of a machine.
+// The machine presents frames to it, and a render loop draws the latest of
+// them at most once a frame.
+type frontend struct {
+	events chan emul8.Event
+	done   <-chan struct{} // Closed once the machine stops serving events.
+	latest atomic.Pointer[emul8.Frame]
+
+	mu      sync.Mutex
+	ring    bool
+	ringing bool
+
+	// held holds the time each held keypad key is released.
+	keyMu sync.Mutex
+	held  map[uint8]time.Time
+}
+
+// Run runs a machine in the terminal until Ctrl-C is pressed. Standard
+// input and output must be a terminal, which is put in raw mode for the
+// run.
+func Run(m *emul8.Machine) error {
+	restore, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return errors.New("standard input is not a terminal: " + err.Error())
+	}
+	defer restore()
+
+	os.Stdout.WriteString(enterScreen)
+	defer os.Stdout.WriteString(leaveScreen)
+
+	f := &frontend{
+		events: make(chan emul8.Event, eventBuffer),
+		held:   make(map[uint8]time.Time),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f.done = ctx.Done()
+
+	// The reader is left blocked on standard input once the run is over.
+	go f.read(os.Stdin, cancel)
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		f.render(ctx, os.Stdout)
+	})
+
+	m.Run(ctx, f, f, f)
+	wg.Wait()
+	return nil
+}
+
+// send passes a request to the machine. Requests beyond those the buffer
+// holds are dropped, except key releases, which wait for room so that no
+// key is left held.
+func (f *frontend) send(ev emul8.Event) {
+	if ev.Kind == emul8.KeyUp {
+		select {
+		case f.events <- ev:
+		case <-f.done:
+		}
+		return
+	}
+
+	select {
+	case f.events <- ev:
+	default:
+	}
+}
+
+// read turns the bytes typed into requests, until Ctrl-C or the end of
+// the input.
+func (f *frontend) read(r io.Reader, quit func()) {
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			quit()
+			return
+		}
+		if !f.parse(string(buf[:n])) {
+			quit()
+			return
+		}
+	}
+}
+
+// parse serves the keys in s, reporting false on Ctrl-C.
+func (f *frontend) parse(s string) bool {
+	for len(s) > 0 {
+		if s[0] == 0x1B {
+			s = f.escape(s)
+			continue
+		}
+
+		r := unicode.ToLower(rune(s[0]))
+		s = s[1:]
+
+		if r == ctrlC {
+			return false
+		}
+		if key, ok := emul8.Keypad[r]; ok {
+			f.press(key)
+		} else if ev, ok := controls[r]; ok {
+			f.send(ev)
+		}
+	}
+	return true
+}
+
+// escape serves the escape sequence at the start of s and returns the
+// rest. Sequences of keys without a use are skipped.
+func (f *frontend) escape(s string) string {
+	for seq, ev := range sequences {
+		if strings.HasPrefix(s, seq) {
+			f.send(ev)
+			return s[len(seq):]
+		}
+	}
+
+	if len(s) < 2 || s[1] != '[' && s[1] != 'O' {
+		return s[1:]
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7E {
+			return s[i+1:]
+		}
+	}
+	return ""
+}
+
+// press holds a keypad key down until keyHold after its last press.
+func (f *frontend) press(key uint8) {
+	f.keyMu.Lock()
+	_, down := f.held[key]
+	f.held[key] = time.Now().Add(keyHold)
+	f.keyMu.Unlock()
+
+	if !down {
+		f.send(emul8.Event{Kind: emul8.KeyDown, Key: key})
+		time.AfterFunc(keyHold, func() { f.release(key) })
+	}
+}
+
+// release lets a key go once keyHold has passed since its last press. The
+// release is sent without holding keyMu, as it may wait for room in events
+// while the reader goes on pressing keys.
+func (f *frontend) release(key uint8) {
+	f.keyMu.Lock()
+	if wait := time.Until(f.held[key]); wait > 0 {
+		f.keyMu.Unlock()
+		time.AfterFunc(wait, func() { f.release(key) })
+		return
+	}
+	delete(f.held, key)
+	f.keyMu.Unlock()
+
+	f.send(emul8.Event{Kind: emul8.KeyUp, Key: key})
+
+	// A press while the release waited may have sent its KeyDown first, so
+	// it is sent again to leave the key down.
+	f.keyMu.Lock()
+	_, down := f.held[key]
+	f.keyMu.Unlock()
+	if down {
+		f.send(emul8.Event{Kind: emul8.KeyDown, Key: key})
+	}
+}
+
+func (f *frontend) Events() <-chan emul8.Event {
+	return f.events
+}
+
+// Present keeps the frame for the next render.
+func (f *frontend) Present(frame *emul8.Frame) {
+	f.latest.Store(frame)
+}
+
+// Start rings the bell as a sound starts.
+func (f *frontend) Start(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.ringing {
+		f.ringing, f.ring = true, true
+	}
+	return nil
+}
+
+func (f *frontend) Stop() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ringing = false
+	return nil
+}
+
+func (f *frontend) SetPattern([chip8.PatternSize]byte, uint8) {}
+
+// render draws the latest frame once a frame while there is a new one,
+// rewriting only the lines that differ from those on the screen.
+func (f *frontend) render(ctx context.Context, w io.Writer) {
+	ticker := time.NewTicker(chip8.TimerRate)
+	defer ticker.Stop()
+
+	var screen []string
+	var last *emul8.Frame
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		f.mu.Lock()
+		ring := f.ring
+		f.ring = false
+		f.mu.Unlock()
+
+		frame := f.latest.Load()
+		if frame == nil || frame == last && !ring {
+			continue
+		}
+		last = frame
+		lines := layout(frame)
+
+		var b strings.Builder
+		if ring {
+			b.WriteByte('\a')
+		}
+		for i := range max(len(lines), len(screen)) {
+			line := ""
+			if i < len(lines) {
+				line = lines[i]
+			}
+			if i < len(screen) && screen[i] == line {
+				continue
+			}
+			b.WriteString("\x1b[" + strconv.Itoa(i+1) + ";1H" + line + clearLine)
+		}
+		screen = lines
+
+		io.WriteString(w, b.String())
+	}
+}
+
+// lines lays out the screen: the display, with the panel to its right,
+// and the keys below.
+func layout(frame *emul8.Frame) []string {
+	display := display(frame)
+	panel := panel(frame)
+
+	lines := make([]string, max(len(display), len(panel)), max(len(display), len(panel))+2)
+	for i := range lines {
+		if i < len(display) {
+			lines[i] = display[i]
+		} else {
+			lines[i] = strings.Repeat(" ", frame.Width)
+		}
+		if i < len(panel) {
+			lines[i] += "  " + panel[i]
+		}
+	}
+	return append(lines, "", help)
+}
+
+// display draws two rows of pixels to a line, the upper pixel in the
+// foreground color and the lower in the background color.
+func display(frame *emul8.Frame) []string {
+	width := frame.Width
+
+	var lines []string
+	for y := 0; y+1 < frame.Height; y += 2 {
+		var b strings.Builder
+		fg, bg := -1, -1
+		for x := range width {
+			top := int(frame.Pixels[y*width+x] & 0x3)
+			bottom := int(frame.Pixels[(y+1)*width+x] & 0x3)
+			if top != fg {
+				b.WriteString(foreground[top])
+				fg = top
+			}
+			if bottom != bg {
+				b.WriteString(background[bottom])
+				bg = bottom
+			}
+			b.WriteString(upperHalf)
+		}
+		b.WriteString(resetColor)
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// panel lists the registers, two columns of V registers, and the most
+// recent messages.
+func panel(frame *emul8.Frame) []string {
+	r := frame.Registers
+	lines := []string{
+		"PC " + byteconv.Btoh(byteconv.U16tob(r.PC), 3) + "  I " + byteconv.Btoh(byteconv.U16tob(r.I), 3) + "  Stack " + strconv.Itoa(r.Stack),
+		"",
+	}
+	for i := range 8 {
+		lines = append(lines, register(i, r.V[i])+"  "+register(i+8, r.V[i+8]))
+	}
+	lines = append(lines, "")
+	return append(lines, frame.Log[:min(len(frame.Log), logLines)]...)
+}
+
+func register(i int, v uint8) string {
+	return "V" + byteconv.Btoh([]byte{uint8(i)}, 1) + " " + byteconv.Btoh([]byte{v}, 2)
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2026 Joshua Jones <joshua.jones.software@gmail.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      www.apache.org
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tui
+
+import (
+	"emul8"
+	"testing"
+	"time"
+)
+
+// TestReleaseDoesNotBlockPress checks that a release waiting for room in a
+// full queue of requests leaves the reader free to press keys.
+func TestReleaseDoesNotBlockPress(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	f := &frontend{
+		events: make(chan emul8.Event, 1),
+		done:   done,
+		held:   map[uint8]time.Time{1: time.Now()},
+	}
+	f.events <- emul8.Event{Kind: emul8.Pause}
+
+	released := make(chan struct{})
+	go func() {
+		f.release(1)
+		close(released)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	pressed := make(chan struct{})
+	go func() {
+		f.press(2)
+		close(pressed)
+	}()
+	select {
+	case <-pressed:
+	case <-time.After(time.Second):
+		t.Fatal("press waited for a release")
+	}
+
+	// Once there is room, the release goes through.
+	<-f.events
+	if ev := <-f.events; ev.Kind != emul8.KeyUp || ev.Key != 1 {
+		t.Errorf("got %+v, want the release of key 1", ev)
+	}
+	<-released
+}